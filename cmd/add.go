@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/babarot/gist/pkg/gist"
+	"github.com/babarot/gist/pkg/spin"
+	"github.com/spf13/cobra"
+)
+
+type addCmd struct {
+	meta
+
+	filename string
+}
+
+// newAddCmd creates a new add command
+func newAddCmd() *cobra.Command {
+	c := &addCmd{}
+
+	addCmd := &cobra.Command{
+		Use:                   "add ID [FILE...]",
+		Short:                 "Add files to an existing gist",
+		Aliases:               []string{},
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.meta.load(args); err != nil {
+				return err
+			}
+			return c.run(args)
+		},
+	}
+
+	f := addCmd.Flags()
+	f.StringVarP(&c.filename, "filename", "f", "", "filename of the added file")
+	f.BoolVar(&c.dryRun, "dry-run", false, "show what would be added without adding")
+
+	return addCmd
+}
+
+func (c *addCmd) run(args []string) error {
+	page, err := c.selectPage(args[:1])
+	if err != nil {
+		return err
+	}
+	page, err = c.checkoutPage(page)
+	if err != nil {
+		return err
+	}
+
+	var files []gist.File
+	switch {
+	case len(args) == 1 && !isTerminal(os.Stdin):
+		files, err = c.withStdin()
+	case len(args) == 1:
+		return errors.New("files or stdin must be given")
+	default:
+		files, err = c.withArgs(args[1:])
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := checkFilename(file.Name); err != nil {
+			return err
+		}
+		for _, f := range page.Files {
+			if f.Name == file.Name {
+				return fmt.Errorf("%s: already exists in gist %s", file.Name, page.ID)
+			}
+		}
+	}
+
+	if c.dryRun {
+		fmt.Printf("Dry run: would add to %s:\n", page.URL)
+		for _, file := range files {
+			fmt.Printf("  - %s (%d bytes)\n", file.Name, len(file.Content))
+		}
+		return nil
+	}
+
+	s := spin.New("%s Adding files...")
+	s.Start()
+	defer s.Stop()
+
+	if err := c.gist.AddFiles(page, files); err != nil {
+		return err
+	}
+
+	c.updatePage(page.ID, func(page *gist.Page) {
+		for _, file := range files {
+			page.Files = append(page.Files, gist.File{Name: file.Name})
+		}
+		page.UpdatedAt = time.Now()
+	})
+
+	s.Stop()
+	fmt.Printf("Pushed: %s\n", page.URL)
+
+	return nil
+}
+
+func (c *addCmd) withStdin() ([]gist.File, error) {
+	var files []gist.File
+	if c.filename == "" {
+		return files, errors.New("--filename is required when reading from stdin")
+	}
+	content, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return files, err
+	}
+	files = append(files, gist.File{
+		Name:    c.filename,
+		Content: string(content),
+	})
+	return files, nil
+}
+
+func (c *addCmd) withArgs(args []string) ([]gist.File, error) {
+	var files []gist.File
+	if c.filename != "" && len(args) > 1 {
+		return files, errors.New("--filename cannot be used with multiple files")
+	}
+	for _, arg := range args {
+		content, err := ioutil.ReadFile(arg)
+		if err != nil {
+			return files, err
+		}
+		name := c.filename
+		if name == "" {
+			name = filepath.Base(arg)
+		}
+		files = append(files, gist.File{
+			Name:    name,
+			Content: string(content),
+		})
+	}
+	return files, nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/babarot/gist/pkg/gist"
+	"github.com/spf13/cobra"
+)
+
+type catCmd struct {
+	meta
+}
+
+// newCatCmd creates a new cat command
+func newCatCmd() *cobra.Command {
+	c := &catCmd{}
+
+	catCmd := &cobra.Command{
+		Use:                   "cat [ID | ID/FILENAME]...",
+		Short:                 "Print gist files to stdout",
+		Aliases:               []string{"view"},
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.meta.load(args); err != nil {
+				return err
+			}
+			return c.run(args)
+		},
+	}
+
+	return catCmd
+}
+
+func (c *catCmd) run(args []string) error {
+	var files []gist.File
+
+	switch len(args) {
+	case 0:
+		file, err := c.prompt()
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+	default:
+		for _, arg := range args {
+			found, err := c.lookup(arg)
+			if err != nil {
+				return err
+			}
+			files = append(files, found...)
+		}
+	}
+
+	for _, file := range files {
+		content, err := c.gist.Read(file)
+		if err != nil {
+			return err
+		}
+		fmt.Print(content)
+	}
+
+	return nil
+}
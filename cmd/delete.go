@@ -2,13 +2,18 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/babarot/gist/pkg/gist"
 	"github.com/babarot/gist/pkg/spin"
 	"github.com/spf13/cobra"
 )
 
 type deleteCmd struct {
 	meta
+
+	all bool
+	yes bool
 }
 
 // newDeleteCmd creates a new delete command
@@ -16,41 +21,121 @@ func newDeleteCmd() *cobra.Command {
 	c := &deleteCmd{}
 
 	deleteCmd := &cobra.Command{
-		Use:                   "delete",
+		Use:                   "delete [ID | ID/FILENAME | URL]",
 		Short:                 "Delete gist file",
 		Aliases:               []string{},
 		DisableFlagsInUseLine: true,
 		SilenceUsage:          true,
 		SilenceErrors:         true,
-		Args:                  cobra.MaximumNArgs(0),
+		Args:                  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := c.meta.init(args); err != nil {
+			if err := c.meta.load(args); err != nil {
 				return err
 			}
 			return c.run(args)
 		},
 	}
 
+	f := deleteCmd.Flags()
+	f.BoolVarP(&c.all, "all", "a", false, "delete the whole page including all files")
+	f.BoolVar(&c.all, "page", false, "alias for --all")
+	f.BoolVarP(&c.yes, "yes", "y", false, "delete without confirmation")
+	f.BoolVar(&c.dryRun, "dry-run", false, "show what would be deleted without deleting")
+
 	return deleteCmd
 }
 
 func (c *deleteCmd) run(args []string) error {
-	file, err := c.prompt()
+	if c.all {
+		page, err := c.selectPage(args)
+		if err != nil {
+			return err
+		}
+		return c.deletePage(page)
+	}
+
+	file, err := c.selectFile(args)
 	if err != nil {
 		return err
 	}
+	file, err = c.checkoutFile(file)
+	if err != nil {
+		return err
+	}
+	return c.deleteFile(file)
+}
+
+// proceed shows what will be lost and asks for confirmation
+func (c *deleteCmd) proceed(page gist.Page, files []gist.File) (bool, error) {
+	fmt.Printf("ID:          %s\n", page.ID)
+	fmt.Printf("Description: %s\n", page.Description)
+	fmt.Printf("Files:\n")
+	for _, file := range files {
+		fmt.Printf("  - %s\n", file.Name)
+	}
+	if c.dryRun {
+		fmt.Println("Dry run: nothing has been deleted")
+		return false, nil
+	}
+	if c.yes {
+		return true, nil
+	}
+	return c.confirm("Delete these files")
+}
+
+func (c *deleteCmd) deletePage(page gist.Page) error {
+	ok, err := c.proceed(page, page.Files)
+	if !ok || err != nil {
+		return err
+	}
 
 	s := spin.New("%s Deleting page...")
 	s.Start()
 	defer s.Stop()
 
-	if err := c.gist.Delete(file.Page); err != nil {
+	if err := c.gist.Delete(page); err != nil {
+		return err
+	}
+
+	s.Stop()
+	fmt.Printf("Deleted (restore with `gist undelete %s`)\n", page.ID)
+	c.cache.Invalidate()
+
+	return nil
+}
+
+func (c *deleteCmd) deleteFile(file gist.File) error {
+	if len(file.Page.Files) < 2 {
+		return fmt.Errorf("%s: the only file in gist %s cannot be deleted, use --all to delete the page",
+			file.Name, file.Page.ID)
+	}
+
+	ok, err := c.proceed(file.Page, []gist.File{file})
+	if !ok || err != nil {
+		return err
+	}
+
+	s := spin.New("%s Deleting file...")
+	s.Start()
+	defer s.Stop()
+
+	if err := c.gist.DeleteFile(file); err != nil {
 		return err
 	}
-	fmt.Println("Deleted")
+
+	c.updatePage(file.Page.ID, func(page *gist.Page) {
+		var files []gist.File
+		for _, f := range page.Files {
+			if f.Name != file.Name {
+				files = append(files, f)
+			}
+		}
+		page.Files = files
+		page.UpdatedAt = time.Now()
+	})
 
 	s.Stop()
-	c.cache.Delete()
+	fmt.Printf("Deleted: %s/%s\n", file.Page.ID, file.Name)
 
 	return nil
 }
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/babarot/gist/pkg/gist"
+	"github.com/babarot/gist/pkg/shell"
+	"github.com/babarot/gist/pkg/spin"
+	"github.com/spf13/cobra"
+)
+
+type describeCmd struct {
+	meta
+}
+
+// newDescribeCmd creates a new describe command
+func newDescribeCmd() *cobra.Command {
+	c := &describeCmd{}
+
+	describeCmd := &cobra.Command{
+		Use:                   "describe [ID | URL] [DESCRIPTION]",
+		Short:                 "Edit gist description",
+		Aliases:               []string{"desc"},
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.meta.load(args); err != nil {
+				return err
+			}
+			return c.run(args)
+		},
+	}
+
+	f := describeCmd.Flags()
+	f.BoolVar(&c.dryRun, "dry-run", false, "show the new description without changing it")
+
+	return describeCmd
+}
+
+func (c *describeCmd) run(args []string) error {
+	page, err := c.selectPage(args)
+	if err != nil {
+		return err
+	}
+
+	var desc string
+	switch len(args) {
+	case 0, 1:
+		desc, err = c.withEditor(page.Description)
+		if err != nil {
+			return err
+		}
+	default:
+		desc = strings.Join(args[1:], " ")
+	}
+
+	if desc == page.Description {
+		return nil
+	}
+
+	fmt.Printf("Old: %s\n", page.Description)
+	fmt.Printf("New: %s\n", desc)
+
+	if c.dryRun {
+		return nil
+	}
+
+	s := spin.New("%s Updating description...")
+	s.Start()
+	defer s.Stop()
+
+	if err := c.gist.Client.EditDescription(page.ID, desc); err != nil {
+		return err
+	}
+
+	c.updatePage(page.ID, func(page *gist.Page) {
+		page.Description = desc
+		page.UpdatedAt = time.Now()
+	})
+
+	return nil
+}
+
+// withEditor opens the editor with the current description to edit
+func (c *describeCmd) withEditor(current string) (string, error) {
+	if options.noInput {
+		return "", errors.New("description must be given when --no-input is given")
+	}
+
+	tmpfile, err := ioutil.TempFile("", "gist")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpfile.Name())
+	defer tmpfile.Close()
+
+	if _, err := tmpfile.WriteString(current + "\n"); err != nil {
+		return "", err
+	}
+
+	editor := shell.New(c.gist.Editor, tmpfile.Name())
+	if err := editor.Run(context.Background()); err != nil {
+		return "", err
+	}
+
+	content, err := ioutil.ReadFile(tmpfile.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
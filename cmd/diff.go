@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+type diffCmd struct {
+	meta
+}
+
+// newDiffCmd creates a new diff command
+func newDiffCmd() *cobra.Command {
+	c := &diffCmd{}
+	c.quiet = true
+
+	diffCmd := &cobra.Command{
+		Use:                   "diff [ID | ID/FILENAME | URL] [REV1 [REV2]]",
+		Short:                 "Show local changes or changes between revisions",
+		Aliases:               []string{},
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.MaximumNArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.meta.load(args); err != nil {
+				return err
+			}
+			return c.run(args)
+		},
+	}
+
+	return diffCmd
+}
+
+func (c *diffCmd) run(args []string) error {
+	page, err := c.selectPage(args)
+	if err != nil {
+		return err
+	}
+	page, err = c.checkoutPage(page)
+	if err != nil {
+		return err
+	}
+	repo, err := page.Repository()
+	if err != nil {
+		return err
+	}
+
+	var name string
+	if len(args) > 0 {
+		_, name = parseQuery(args[0])
+	}
+
+	if len(args) > 1 {
+		// older revisions may not be fetched yet
+		if err := repo.Deepen(context.Background()); err != nil {
+			return err
+		}
+	}
+
+	var diff string
+	switch len(args) {
+	case 0, 1:
+		diff, err = repo.Diff(name)
+	case 2:
+		diff, err = repo.DiffRevisions(args[1], "", name)
+	default:
+		diff, err = repo.DiffRevisions(args[1], args[2], name)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(diff)
+	return nil
+}
@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
 
+	"github.com/babarot/gist/pkg/gist"
 	"github.com/babarot/gist/pkg/shell"
 	"github.com/babarot/gist/pkg/spin"
 	"github.com/spf13/cobra"
@@ -11,6 +15,9 @@ import (
 
 type editCmd struct {
 	meta
+
+	review  bool
+	message string
 }
 
 // newEditCmd creates a new edit command
@@ -18,26 +25,35 @@ func newEditCmd() *cobra.Command {
 	c := &editCmd{}
 
 	editCmd := &cobra.Command{
-		Use:                   "edit",
+		Use:                   "edit [ID | ID/FILENAME | URL]",
 		Short:                 "Edit gist files",
 		Aliases:               []string{},
 		DisableFlagsInUseLine: true,
 		SilenceUsage:          true,
 		SilenceErrors:         true,
-		Args:                  cobra.MaximumNArgs(0),
+		Args:                  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := c.meta.init(args); err != nil {
+			if err := c.meta.load(args); err != nil {
 				return err
 			}
 			return c.run(args)
 		},
 	}
 
+	f := editCmd.Flags()
+	f.BoolVar(&c.dryRun, "dry-run", false, "edit locally without pushing")
+	f.BoolVar(&c.review, "confirm", false, "show the diff and confirm before pushing")
+	f.StringVarP(&c.message, "message", "m", "", "commit message (generated from the changes by default)")
+
 	return editCmd
 }
 
 func (c *editCmd) run(args []string) error {
-	file, err := c.prompt()
+	file, err := c.selectFile(args)
+	if err != nil {
+		return err
+	}
+	file, err = c.checkoutFile(file)
 	if err != nil {
 		return err
 	}
@@ -56,18 +72,84 @@ func (c *editCmd) run(args []string) error {
 		return nil
 	}
 
+	if c.dryRun {
+		fmt.Printf("Dry run: would push %s to %s\n", file.Name, file.URL)
+		return nil
+	}
+
+	if c.review {
+		diff, err := file.Page.Repo.Diff(file.Name)
+		if err != nil {
+			return err
+		}
+		fmt.Print(diff)
+		ok, err := c.confirm("Push these changes")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Printf("Not pushed, the changes are kept in %s (push later with `gist push %s`)\n",
+				file.FullPath, file.Page.ID)
+			return nil
+		}
+	}
+
 	s := spin.New("%s Pushing...")
 	s.Start()
 	defer s.Stop()
 
-	if err := file.Update(); err != nil {
+	err = file.Update(c.message)
+	s.Stop()
+	if errors.Is(err, gist.ErrConflict) {
+		err = c.resolve(file)
+	}
+	if err != nil {
 		return err
 	}
 
 	c.UpdateCache(file)
 
-	s.Stop()
 	fmt.Printf("Pushed: %s\n", file.URL)
 
 	return nil
 }
+
+// resolve lets the user resolve the conflicts in the editor and pushes the result.
+// All files left with conflict markers are opened since all changes brought back
+// by the merge are committed together
+func (c *editCmd) resolve(file gist.File) error {
+	repo := file.Page.Repo
+	conflicts, err := repo.Conflicts()
+	if err != nil {
+		return err
+	}
+	var paths []string
+	for _, name := range conflicts {
+		paths = append(paths, filepath.Join(repo.Path(), name))
+	}
+
+	fmt.Printf("%s has been changed on the remote and has conflicts, resolve them in the editor\n", file.Page.ID)
+	if options.noInput {
+		return fmt.Errorf("%s: %w, resolve the conflict markers in %s and run `gist push %s`",
+			file.Page.ID, gist.ErrConflict, strings.Join(paths, ", "), file.Page.ID)
+	}
+
+	editor := shell.New(c.gist.Editor, paths...)
+	if err := editor.Run(context.Background()); err != nil {
+		return err
+	}
+
+	conflicts, err = repo.Conflicts()
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%s: conflict markers remain in %s", file.Page.ID, strings.Join(conflicts, ", "))
+	}
+
+	s := spin.New("%s Pushing...")
+	s.Start()
+	defer s.Stop()
+
+	return file.Page.Push(c.message)
+}
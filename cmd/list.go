@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/babarot/gist/pkg/gist"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+type listCmd struct {
+	meta
+
+	format   string
+	template string
+}
+
+// listItem is a flattened view of a gist page used for output
+type listItem struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description"`
+	Public      bool      `json:"public"`
+	Visibility  string    `json:"visibility"`
+	Files       []string  `json:"files"`
+	URL         string    `json:"url"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// newListCmd creates a new list command
+func newListCmd() *cobra.Command {
+	c := &listCmd{}
+
+	listCmd := &cobra.Command{
+		Use:                   "list",
+		Short:                 "List gist pages",
+		Aliases:               []string{"ls"},
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.MaximumNArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.meta.load(args); err != nil {
+				return err
+			}
+			return c.run(args)
+		},
+	}
+
+	f := listCmd.Flags()
+	f.StringVarP(&c.format, "format", "f", "table", "output format (table, json, tsv)")
+	f.StringVarP(&c.template, "template", "t", "", "format each page with a Go template")
+
+	return listCmd
+}
+
+func (c *listCmd) run(args []string) error {
+	var items []listItem
+	for _, page := range c.gist.Pages {
+		items = append(items, newListItem(page))
+	}
+
+	if c.template != "" {
+		return renderTemplate(os.Stdout, c.template, items)
+	}
+
+	switch c.format {
+	case "table":
+		return renderTable(os.Stdout, items)
+	case "json":
+		return renderJSON(os.Stdout, items)
+	case "tsv":
+		return renderTSV(os.Stdout, items)
+	default:
+		return fmt.Errorf("%s: unknown format", c.format)
+	}
+}
+
+func newListItem(page gist.Page) listItem {
+	var files []string
+	for _, file := range page.Files {
+		files = append(files, file.Name)
+	}
+	return listItem{
+		ID:          page.ID,
+		Description: page.Description,
+		Public:      page.Public,
+		Visibility:  visibility(page.Public),
+		Files:       files,
+		URL:         page.URL,
+		UpdatedAt:   page.UpdatedAt,
+	}
+}
+
+// visibility returns the name of the visibility of a page
+func visibility(public bool) string {
+	if public {
+		return "public"
+	}
+	return "secret"
+}
+
+// oneline squashes whitespaces so that a value fits in a single column
+func oneline(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func renderTable(w io.Writer, items []listItem) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tDESCRIPTION\tVISIBILITY\tFILES\tUPDATED")
+	for _, item := range items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			item.ID,
+			oneline(item.Description),
+			item.Visibility,
+			strings.Join(item.Files, ", "),
+			humanize.Time(item.UpdatedAt),
+		)
+	}
+	return tw.Flush()
+}
+
+func renderTSV(w io.Writer, items []listItem) error {
+	for _, item := range items {
+		_, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			item.ID,
+			oneline(item.Description),
+			item.Visibility,
+			strings.Join(item.Files, ","),
+			item.UpdatedAt.Format(time.RFC3339),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderJSON(w io.Writer, items []listItem) error {
+	if items == nil {
+		items = []listItem{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(items)
+}
+
+func renderTemplate(w io.Writer, text string, items []listItem) error {
+	funcMap := template.FuncMap{
+		"join": strings.Join,
+		"time": humanize.Time,
+	}
+	tmpl, err := template.New("list").Funcs(funcMap).Parse(text)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := tmpl.Execute(w, item); err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
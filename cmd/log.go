@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/babarot/gist/pkg/git"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+type logCmd struct {
+	meta
+
+	format string
+}
+
+// newLogCmd creates a new log command
+func newLogCmd() *cobra.Command {
+	c := &logCmd{}
+
+	logCmd := &cobra.Command{
+		Use:                   "log [ID | ID/FILENAME | URL] [FILENAME]",
+		Short:                 "Show revision history of gist",
+		Aliases:               []string{"history"},
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.meta.load(args); err != nil {
+				return err
+			}
+			return c.run(args)
+		},
+	}
+
+	f := logCmd.Flags()
+	f.StringVarP(&c.format, "format", "f", "table", "output format (table, json)")
+
+	return logCmd
+}
+
+func (c *logCmd) run(args []string) error {
+	page, err := c.selectPage(args)
+	if err != nil {
+		return err
+	}
+	page, err = c.checkoutPage(page)
+	if err != nil {
+		return err
+	}
+
+	var name string
+	switch len(args) {
+	case 0:
+	case 1:
+		_, name = parseQuery(args[0])
+	default:
+		name = args[1]
+	}
+
+	revisions, err := c.gist.History(page, name)
+	if err != nil {
+		return err
+	}
+
+	switch c.format {
+	case "table":
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "REVISION\tDATE\tAUTHOR\tCHANGES\tFILES")
+		for _, rev := range revisions {
+			var files []string
+			for _, file := range rev.Files {
+				files = append(files, file.Name)
+			}
+			hash := rev.Hash
+			if len(hash) > 7 {
+				hash = hash[:7]
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t+%d -%d\t%s\n",
+				hash,
+				humanize.Time(rev.Date),
+				rev.Author,
+				rev.Additions,
+				rev.Deletions,
+				strings.Join(files, ", "),
+			)
+		}
+		return tw.Flush()
+	case "json":
+		if revisions == nil {
+			revisions = []git.Revision{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(revisions)
+	default:
+		return fmt.Errorf("%s: unknown format", c.format)
+	}
+}
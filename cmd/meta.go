@@ -1,13 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net/url"
 	"os"
+	"os/signal"
+	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/babarot/gist/pkg/gist"
+	"github.com/babarot/gist/pkg/git"
 	"github.com/babarot/gist/pkg/spin"
 	"github.com/dustin/go-humanize"
 	"github.com/manifoldco/promptui"
@@ -19,10 +27,228 @@ type meta struct {
 	files []gist.File
 
 	cache *gist.Cache
+
+	dryRun bool
+
+	// quiet suppresses the warnings for the pages
+	// left untouched on checkout to keep local changes
+	quiet bool
 }
 
+// init prepares everything and checks out all pages
 func (m *meta) init(args []string) error {
-	workDir := filepath.Join(os.Getenv("HOME"), ".gist")
+	if err := m.load(args); err != nil {
+		return err
+	}
+	_, err := m.checkout(m.gist.Pages...)
+	return err
+}
+
+// checkout clones or updates the repositories of the given pages only
+// and returns them checked out
+func (m *meta) checkout(pages ...gist.Page) ([]gist.Page, error) {
+	if m.gist.AuthorName == "" && m.gist.AuthorEmail == "" {
+		author := m.author()
+		m.gist.AuthorName = author.Name
+		m.gist.AuthorEmail = author.Email
+	}
+
+	// stop checking out on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	g := m.gist
+	g.Pages = pages
+
+	p := spin.NewProgress("Checking pages", len(pages)).Start()
+	errs := g.Checkout(ctx, func(gist.Page) { p.Increment() })
+	p.Stop()
+
+	if ctx.Err() != nil {
+		return nil, errors.New("interrupted")
+	}
+
+	m.report(errs)
+	m.recordFailures(g.Pages)
+
+	checked := make(map[string]gist.Page)
+	for _, page := range g.Pages {
+		checked[page.ID] = page
+	}
+	for i, page := range m.gist.Pages {
+		if page, ok := checked[page.ID]; ok {
+			m.gist.Pages[i] = page
+		}
+	}
+	m.files = m.gist.Files()
+
+	return g.Pages, nil
+}
+
+// recordFailures saves the checkout failures of the pages in the cache
+// so that they're marked as broken in the prompt on the next run
+func (m *meta) recordFailures(pages []gist.Page) {
+	if m.cache.Failures == nil {
+		m.cache.Failures = make(map[string]string)
+	}
+	for _, page := range pages {
+		if page.Err != nil {
+			m.cache.Failures[page.ID] = page.Err.Error()
+		} else {
+			delete(m.cache.Failures, page.ID)
+		}
+	}
+	m.cache.Save(m.cache.Pages)
+}
+
+// checkoutPage checks out the page and returns it checked out
+func (m *meta) checkoutPage(page gist.Page) (gist.Page, error) {
+	pages, err := m.checkout(page)
+	if err != nil {
+		return page, err
+	}
+	return pages[0], nil
+}
+
+// checkoutFile checks out the page of the file and returns the file checked out
+func (m *meta) checkoutFile(file gist.File) (gist.File, error) {
+	if _, err := m.checkout(file.Page); err != nil {
+		return file, err
+	}
+	for _, f := range m.files {
+		if f.Page.ID == file.Page.ID && f.Name == file.Name {
+			return f, nil
+		}
+	}
+	return file, fmt.Errorf("%s: no such file in %s", file.Name, file.Page.ID)
+}
+
+// checkoutCloned checks out the pages which have been cloned locally
+func (m *meta) checkoutCloned() ([]gist.Page, error) {
+	var pages []gist.Page
+	for _, page := range m.gist.Pages {
+		if m.gist.IsCloned(page) {
+			pages = append(pages, page)
+		}
+	}
+	return m.checkout(pages...)
+}
+
+// report shows the errors occurred on checkout. The details of
+// the failures are shown only with --verbose
+func (m *meta) report(errs []gist.PageError) {
+	var failed []gist.PageError
+	for _, err := range errs {
+		if errors.Is(err, git.ErrLocalChanges) {
+			if !m.quiet {
+				fmt.Fprintf(os.Stderr, "[WARN]: %s (%s) was left untouched: %v\n",
+					err.Page.ID, err.Page.Description, err.Err)
+			}
+			continue
+		}
+		if err.Page.Repo != nil {
+			// the local repository can still be used
+			fmt.Fprintf(os.Stderr, "[WARN]: failed to update %s (%s): %v\n",
+				err.Page.ID, err.Page.Description, err.Err)
+			continue
+		}
+		failed = append(failed, err)
+	}
+	if len(failed) == 0 {
+		return
+	}
+	if !options.verbose {
+		fmt.Fprintf(os.Stderr, "[WARN]: failed to check out %d page(s), run with --verbose for details\n", len(failed))
+		return
+	}
+	for _, err := range failed {
+		fmt.Fprintf(os.Stderr, "[WARN]: failed to check out %s (%s): %v\n",
+			err.Page.ID, err.Page.Description, err.Err)
+	}
+}
+
+// author returns the identity used for commits. It's taken from
+// GIST_AUTHOR_NAME/GIST_AUTHOR_EMAIL, the global git config or
+// the GitHub user profile in this order
+func (m *meta) author() gist.Author {
+	author := gist.Author{
+		Name:  os.Getenv("GIST_AUTHOR_NAME"),
+		Email: os.Getenv("GIST_AUTHOR_EMAIL"),
+	}
+	if author.Name != "" && author.Email != "" {
+		return author
+	}
+
+	name, email := git.GlobalAuthor()
+	if name != "" && email != "" {
+		if author.Name == "" {
+			author.Name = name
+		}
+		if author.Email == "" {
+			author.Email = email
+		}
+		return author
+	}
+
+	cached := m.cache.Author
+	if cached.Name == "" || cached.Email == "" {
+		name, email, err := m.gist.Client.Author()
+		if err != nil {
+			// commits can still be made without author
+			return author
+		}
+		cached = gist.Author{Name: name, Email: email}
+		m.cache.Author = cached
+		m.cache.Save(m.cache.Pages)
+	}
+	if author.Name == "" {
+		author.Name = cached.Name
+	}
+	if author.Email == "" {
+		author.Email = cached.Email
+	}
+	return author
+}
+
+const (
+	// defaultConcurrency is the number of pages checked out at the same time
+	defaultConcurrency = 8
+	// defaultTimeout limits the time to check out a page
+	defaultTimeout = 60 * time.Second
+	// defaultDepth is the number of commits cloned for a page
+	defaultDepth = 1
+	// defaultCacheTTL is how long the cached pages are used without
+	// checking the API for updates
+	defaultCacheTTL = time.Hour
+)
+
+// envInt returns the integer set in the environment variable
+func envInt(key string, fallback int) int {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n < 0 {
+		return fallback
+	}
+	return n
+}
+
+// envDuration returns the duration set in the environment variable
+func envDuration(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
+// workDir returns the directory where gist stores its data
+func workDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".gist")
+}
+
+// load prepares the cache, the API client and the list of pages
+// without checking out any repository
+func (m *meta) load(args []string) error {
+	workDir := workDir()
 	cache := gist.NewCache(filepath.Join(workDir, "cache.json"))
 	// load cache
 	cache.Open()
@@ -44,16 +270,34 @@ func (m *meta) init(args []string) error {
 	client := gist.NewClient(token)
 
 	var pages []gist.Page
-	switch len(cache.Pages) {
-	case 0:
+	switch {
+	case len(cache.Pages) == 0, cache.FetchedAt.IsZero(), options.refresh:
 		s := spin.New("%s Fetching pages...")
 		s.Start()
-		results, err := client.List(user)
+		fetchedAt := time.Now()
+		results, err := client.List(user, time.Time{}, cache)
 		s.Stop()
 		if err != nil {
 			return err
 		}
 		pages = results
+		cache.FetchedAt = fetchedAt
+	case cache.IsExpired(envDuration("GIST_CACHE_TTL", defaultCacheTTL)):
+		// only the pages updated since the last fetch are listed,
+		// so the pages deleted on the web remain until --refresh
+		s := spin.New("%s Updating pages...")
+		s.Start()
+		fetchedAt := time.Now()
+		results, err := client.List(user, cache.FetchedAt, cache)
+		s.Stop()
+		if err != nil {
+			// the cache is still usable while offline
+			fmt.Fprintf(os.Stderr, "[WARN]: failed to update pages: %v\n", err)
+			pages = cache.Pages
+			break
+		}
+		pages = cache.Merge(results)
+		cache.FetchedAt = fetchedAt
 	default:
 		pages = cache.Pages
 	}
@@ -61,6 +305,17 @@ func (m *meta) init(args []string) error {
 	// update cache
 	cache.Save(pages)
 
+	// mark the pages which failed to be checked out last time
+	for i, page := range pages {
+		if msg, ok := cache.Failures[page.ID]; ok {
+			pages[i].Err = errors.New(msg)
+		}
+	}
+
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].CreatedAt.After(pages[j].CreatedAt)
+	})
+
 	gist := gist.Gist{
 		User:    user,
 		Token:   token,
@@ -68,12 +323,13 @@ func (m *meta) init(args []string) error {
 		Client:  client,
 		WorkDir: workDir,
 		Pages:   pages,
-	}
+		Trash:   gist.NewTrash(filepath.Join(workDir, "trash")),
+		Retries: 1,
 
-	s := spin.New("%s Checking pages...")
-	s.Start()
-	defer s.Stop()
-	gist.Checkout()
+		Concurrency: envInt("GIST_CONCURRENCY", defaultConcurrency),
+		Timeout:     envDuration("GIST_TIMEOUT", defaultTimeout),
+		Depth:       envInt("GIST_CLONE_DEPTH", defaultDepth),
+	}
 
 	m.gist = gist
 	m.files = gist.Files()
@@ -94,6 +350,52 @@ func (m *meta) UpdateCache(file gist.File) {
 	m.cache.Save(pages)
 }
 
+// updatePage applies fn to the cached page having the given ID and saves the cache
+func (m *meta) updatePage(id string, fn func(page *gist.Page)) {
+	var pages []gist.Page
+	for _, page := range m.cache.Pages {
+		if page.ID == id {
+			fn(&page)
+		}
+		pages = append(pages, page)
+	}
+	m.cache.Save(pages)
+}
+
+// confirm asks the user for a yes/no answer
+func (m *meta) confirm(label string) (bool, error) {
+	if options.noInput {
+		return false, errors.New("confirmation is required, use --yes to proceed without input")
+	}
+	prompt := promptui.Prompt{
+		Label:     label,
+		IsConfirm: true,
+	}
+	_, err := prompt.Run()
+	switch err {
+	case nil:
+		return true, nil
+	case promptui.ErrAbort:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// checkFilename returns an error if the name cannot be used as a gist file,
+// which is placed directly in the repository
+func checkFilename(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("%s: invalid filename", name)
+	}
+	return nil
+}
+
+// isTerminal reports whether the given file is connected to a terminal
+func isTerminal(f *os.File) bool {
+	return terminal.IsTerminal(int(f.Fd()))
+}
+
 func head(content string) string {
 	wrap := func(line string) string {
 		line = strings.ReplaceAll(line, "\t", "  ")
@@ -120,26 +422,36 @@ func head(content string) string {
 }
 
 func (m *meta) prompt() (gist.File, error) {
+	return m.promptFiles(m.files)
+}
+
+func (m *meta) promptFiles(files []gist.File) (gist.File, error) {
+	if options.noInput {
+		return gist.File{}, errors.New("gist must be specified as an argument when --no-input is given")
+	}
 	funcMap := promptui.FuncMap
 	funcMap["head"] = head
 	funcMap["time"] = humanize.Time
 	templates := &promptui.SelectTemplates{
 		Label:    "{{ . }}",
-		Active:   promptui.IconSelect + " {{ .Name | cyan }}",
-		Inactive: "  {{ .Name | faint }}",
+		Active:   promptui.IconSelect + " {{ .Name | cyan }}{{ if .Page.Err }} {{ \"(broken)\" | red }}{{ end }}",
+		Inactive: "  {{ .Name | faint }}{{ if .Page.Err }} {{ \"(broken)\" | red }}{{ end }}",
 		Selected: promptui.IconGood + " {{ .Name }}",
 		Details: `
 {{ "ID:" | faint }}	{{ .Page.ID }}
 {{ "Description:" | faint }}	{{ .Page.Description }}
 {{ "Private:" | faint }}	{{ not .Page.Public }}
 {{ "Last modified:" | faint }}	{{ .Page.UpdatedAt | time }}
+{{- if .Page.Err }}
+{{ "Error:" | faint }}	{{ .Page.Err | red }}
+{{- end }}
 {{ "Content:" | faint }}	{{ .Content | head }}
 		`,
 		FuncMap: funcMap,
 	}
 
 	searcher := func(input string, index int) bool {
-		file := m.files[index]
+		file := files[index]
 		name := strings.Replace(strings.ToLower(file.Name), " ", "", -1)
 		input = strings.Replace(strings.ToLower(input), " ", "", -1)
 		return strings.Contains(name, input)
@@ -147,14 +459,82 @@ func (m *meta) prompt() (gist.File, error) {
 
 	prompt := promptui.Select{
 		Label:             "Select a page",
-		Items:             m.files,
+		Items:             files,
 		Templates:         templates,
 		Searcher:          searcher,
 		StartInSearchMode: true,
 		HideSelected:      true,
 	}
 	i, _, err := prompt.Run()
-	return m.files[i], err
+	if err != nil {
+		return gist.File{}, err
+	}
+	return files[i], nil
+}
+
+// selectFile returns a single file specified by the arguments,
+// asking the user to choose one when it's ambiguous
+func (m *meta) selectFile(args []string) (gist.File, error) {
+	if len(args) == 0 {
+		return m.prompt()
+	}
+	files, err := m.lookup(args[0])
+	if err != nil {
+		return gist.File{}, err
+	}
+	if len(files) == 1 {
+		return files[0], nil
+	}
+	if options.noInput {
+		return gist.File{}, fmt.Errorf("%s: gist has multiple files, specify ID/FILENAME", args[0])
+	}
+	return m.promptFiles(files)
+}
+
+// selectPage returns the page specified by the arguments
+func (m *meta) selectPage(args []string) (gist.Page, error) {
+	if len(args) == 0 {
+		file, err := m.prompt()
+		return file.Page, err
+	}
+	files, err := m.lookup(args[0])
+	if err != nil {
+		return gist.Page{}, err
+	}
+	return files[0].Page, nil
+}
+
+// parseQuery splits the query into a gist ID and a filename.
+// The query is either a gist ID, a gist ID followed by a filename
+// ("id/filename") or the HTML URL of the gist
+func parseQuery(query string) (id, name string) {
+	if u, err := url.Parse(query); err == nil && u.Host != "" {
+		// e.g. https://gist.github.com/user/id
+		return path.Base(u.Path), ""
+	}
+	if i := strings.Index(query, "/"); i >= 0 {
+		return query[:i], query[i+1:]
+	}
+	return query, ""
+}
+
+// lookup returns the files matching the given query (see parseQuery)
+func (m *meta) lookup(query string) ([]gist.File, error) {
+	id, name := parseQuery(query)
+	var files []gist.File
+	for _, file := range m.files {
+		if file.Page.ID != id {
+			continue
+		}
+		if name != "" && file.Name != name {
+			continue
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return files, fmt.Errorf("%s: no such gist", query)
+	}
+	return files, nil
 }
 
 func (m *meta) githubToken() (string, error) {
@@ -170,6 +550,9 @@ func (m *meta) githubToken() (string, error) {
 	if token != "" {
 		return token, nil
 	}
+	if options.noInput {
+		return "", errors.New("GITHUB_TOKEN is not set")
+	}
 	prompt := promptui.Prompt{
 		Label: "GITHUB_TOKEN",
 		Mask:  '*',
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/babarot/gist/pkg/gist"
+	"github.com/babarot/gist/pkg/spin"
+	"github.com/spf13/cobra"
+)
+
+type mvCmd struct {
+	meta
+}
+
+// newMvCmd creates a new mv command
+func newMvCmd() *cobra.Command {
+	c := &mvCmd{}
+
+	mvCmd := &cobra.Command{
+		Use:                   "mv ID/FILENAME NEWNAME",
+		Short:                 "Rename a file in gist",
+		Aliases:               []string{"rename"},
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.meta.load(args); err != nil {
+				return err
+			}
+			return c.run(args)
+		},
+	}
+
+	f := mvCmd.Flags()
+	f.BoolVar(&c.dryRun, "dry-run", false, "show what would be renamed without renaming")
+
+	return mvCmd
+}
+
+func (c *mvCmd) run(args []string) error {
+	file, err := c.selectFile(args[:1])
+	if err != nil {
+		return err
+	}
+	file, err = c.checkoutFile(file)
+	if err != nil {
+		return err
+	}
+
+	name := args[1]
+	if err := checkFilename(name); err != nil {
+		return err
+	}
+	for _, f := range file.Page.Files {
+		if f.Name == name {
+			return fmt.Errorf("%s: already exists in gist %s", name, file.Page.ID)
+		}
+	}
+
+	if c.dryRun {
+		fmt.Printf("Dry run: would rename %s/%s to %s\n", file.Page.ID, file.Name, name)
+		return nil
+	}
+
+	s := spin.New("%s Renaming file...")
+	s.Start()
+	defer s.Stop()
+
+	if err := c.gist.Rename(file, name); err != nil {
+		return err
+	}
+
+	c.updatePage(file.Page.ID, func(page *gist.Page) {
+		for i, f := range page.Files {
+			if f.Name == file.Name {
+				page.Files[i].Name = name
+			}
+		}
+		page.UpdatedAt = time.Now()
+	})
+
+	s.Stop()
+	fmt.Printf("Renamed: %s/%s -> %s\n", file.Page.ID, file.Name, name)
+
+	return nil
+}
@@ -15,10 +15,15 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// defaultFilename is used when the filename cannot be asked
+const defaultFilename = "gistfile1.txt"
+
 type newCmd struct {
 	meta
 
-	private bool
+	private     bool
+	filename    string
+	description string
 
 	validator promptui.ValidateFunc
 }
@@ -35,10 +40,7 @@ func newNewCmd() *cobra.Command {
 		SilenceUsage:          true,
 		SilenceErrors:         true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO:
-			// new command doesn't need to fetch all repos data
-			// so divide this function to init and load.
-			if err := c.meta.init(args); err != nil {
+			if err := c.meta.load(args); err != nil {
 				return err
 			}
 			return c.run(args)
@@ -47,6 +49,9 @@ func newNewCmd() *cobra.Command {
 
 	f := newCmd.Flags()
 	f.BoolVarP(&c.private, "private", "p", false, "make private")
+	f.StringVarP(&c.filename, "filename", "f", "", "filename of the gist file")
+	f.StringVarP(&c.description, "description", "d", "", "description of the gist")
+	f.BoolVar(&c.dryRun, "dry-run", false, "show what would be created without creating")
 
 	return newCmd
 }
@@ -62,25 +67,40 @@ func (c *newCmd) run(args []string) error {
 	var files []gist.File
 	var err error
 
-	// TODO: Check pipe to allow to give from STDIN
-	// gist new < gist.md
-	switch len(args) {
-	case 0:
+	interactive := isTerminal(os.Stdin) && !options.noInput
+
+	switch {
+	case !isTerminal(os.Stdin) && len(args) == 0:
+		files, err = c.withStdin()
+	case options.noInput && len(args) == 0:
+		return errors.New("files or stdin must be given when --no-input is given")
+	case len(args) == 0:
 		files, err = c.withNoArg()
 	default:
-		files, err = c.withArgs(args)
+		files, err = c.withArgs(args, interactive)
 	}
 	if err != nil {
 		return err
 	}
 
-	prompt := promptui.Prompt{
-		Label:    "Description",
-		Validate: c.validator,
+	desc := c.description
+	if desc == "" && interactive {
+		prompt := promptui.Prompt{
+			Label:    "Description",
+			Validate: c.validator,
+		}
+		desc, err = prompt.Run()
+		if err != nil {
+			return err
+		}
 	}
-	desc, err := prompt.Run()
-	if err != nil {
-		return err
+
+	if c.dryRun {
+		fmt.Printf("Dry run: would create a %s page %q with:\n", visibility(!c.private), desc)
+		for _, file := range files {
+			fmt.Printf("  - %s (%d bytes)\n", file.Name, len(file.Content))
+		}
+		return nil
 	}
 
 	s := spin.New("%s Creating page...")
@@ -99,7 +119,7 @@ func (c *newCmd) run(args []string) error {
 	s.Stop()
 	fmt.Println(url)
 
-	c.cache.Delete()
+	c.cache.Invalidate()
 	return nil
 }
 
@@ -122,15 +142,43 @@ func (c *newCmd) withNoArg() ([]gist.File, error) {
 		return files, err
 	}
 
-	prompt := promptui.Prompt{
-		Label:    "Filename",
-		Validate: c.validator,
+	name := c.filename
+	if name == "" {
+		prompt := promptui.Prompt{
+			Label:    "Filename",
+			Validate: c.validator,
+		}
+		name, err = prompt.Run()
+		if err != nil {
+			return files, err
+		}
 	}
 
-	name, err := prompt.Run()
+	files = append(files, gist.File{
+		Name:    name,
+		Content: string(content),
+	})
+
+	return files, nil
+}
+
+// withStdin reads the content from piped stdin, e.g. gist new < gist.md
+func (c *newCmd) withStdin() ([]gist.File, error) {
+	var files []gist.File
+
+	content, err := ioutil.ReadAll(os.Stdin)
 	if err != nil {
 		return files, err
 	}
+	if len(content) == 0 {
+		return files, errors.New("stdin is empty")
+	}
+
+	// prompts cannot be shown as stdin is not a terminal
+	name := c.filename
+	if name == "" {
+		name = defaultFilename
+	}
 
 	files = append(files, gist.File{
 		Name:    name,
@@ -140,9 +188,15 @@ func (c *newCmd) withNoArg() ([]gist.File, error) {
 	return files, nil
 }
 
-func (c *newCmd) withArgs(args []string) ([]gist.File, error) {
+// withArgs reads the content from the files. The filenames are asked
+// only if interactive, otherwise the base names of the files are used
+func (c *newCmd) withArgs(args []string, interactive bool) ([]gist.File, error) {
 	var files []gist.File
 
+	if c.filename != "" && len(args) > 1 {
+		return files, errors.New("--filename cannot be used with multiple files")
+	}
+
 	for _, arg := range args {
 		f, err := os.Open(arg)
 		if err != nil {
@@ -155,15 +209,21 @@ func (c *newCmd) withArgs(args []string) ([]gist.File, error) {
 			return files, err
 		}
 
-		prompt := promptui.Prompt{
-			Label:     "Filename",
-			Validate:  c.validator,
-			AllowEdit: true,
-			Default:   filepath.Base(arg),
+		name := c.filename
+		if name == "" && !interactive {
+			name = filepath.Base(arg)
 		}
-		name, err := prompt.Run()
-		if err != nil {
-			return files, err
+		if name == "" {
+			prompt := promptui.Prompt{
+				Label:     "Filename",
+				Validate:  c.validator,
+				AllowEdit: true,
+				Default:   filepath.Base(arg),
+			}
+			name, err = prompt.Run()
+			if err != nil {
+				return files, err
+			}
 		}
 
 		files = append(files, gist.File{
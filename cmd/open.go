@@ -14,15 +14,15 @@ func newOpenCmd() *cobra.Command {
 	c := &openCmd{}
 
 	openCmd := &cobra.Command{
-		Use:                   "open",
+		Use:                   "open [ID | ID/FILENAME | URL]",
 		Short:                 "Open gist file",
 		Aliases:               []string{},
 		DisableFlagsInUseLine: true,
 		SilenceUsage:          true,
 		SilenceErrors:         true,
-		Args:                  cobra.MaximumNArgs(0),
+		Args:                  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := c.meta.init(args); err != nil {
+			if err := c.meta.load(args); err != nil {
 				return err
 			}
 			return c.run(args)
@@ -33,9 +33,9 @@ func newOpenCmd() *cobra.Command {
 }
 
 func (c *openCmd) run(args []string) error {
-	file, err := c.prompt()
+	page, err := c.selectPage(args)
 	if err != nil {
 		return err
 	}
-	return browser.OpenURL(file.Page.URL)
+	return browser.OpenURL(page.URL)
 }
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/babarot/gist/pkg/gist"
+	"github.com/babarot/gist/pkg/spin"
+	"github.com/spf13/cobra"
+)
+
+type pushCmd struct {
+	meta
+
+	message string
+}
+
+// newPushCmd creates a new push command
+func newPushCmd() *cobra.Command {
+	c := &pushCmd{}
+	c.quiet = true
+
+	pushCmd := &cobra.Command{
+		Use:                   "push [ID | URL]...",
+		Short:                 "Commit and push local changes",
+		Aliases:               []string{},
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.meta.load(args); err != nil {
+				return err
+			}
+			return c.run(args)
+		},
+	}
+
+	f := pushCmd.Flags()
+	f.BoolVar(&c.dryRun, "dry-run", false, "show what would be pushed without pushing")
+	f.StringVarP(&c.message, "message", "m", "", "commit message (generated from the changes by default)")
+
+	return pushCmd
+}
+
+func (c *pushCmd) run(args []string) error {
+	var pages []gist.Page
+	var err error
+	switch len(args) {
+	case 0:
+		pages, err = c.checkoutCloned()
+	default:
+		for _, arg := range args {
+			page, err := c.selectPage([]string{arg})
+			if err != nil {
+				return err
+			}
+			pages = append(pages, page)
+		}
+		pages, err = c.checkout(pages...)
+	}
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, page := range pages {
+		if page.Repo == nil && len(args) == 0 {
+			continue
+		}
+		ok, err := page.HasChanges()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR]: %s: %v\n", page.ID, err)
+			failed++
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		if c.dryRun {
+			fmt.Printf("Dry run: would push %s\n", page.URL)
+			continue
+		}
+
+		s := spin.New(fmt.Sprintf("%%s Pushing %s...", page.ID))
+		s.Start()
+		err = page.Push(c.message)
+		s.Stop()
+		if errors.Is(err, gist.ErrConflict) {
+			err = fmt.Errorf("%w, resolve the conflict markers in %s and push again", err, page.Repo.Path())
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR]: %s: %v\n", page.ID, err)
+			failed++
+			continue
+		}
+
+		c.updatePage(page.ID, func(page *gist.Page) {
+			page.UpdatedAt = time.Now()
+		})
+		fmt.Printf("Pushed: %s\n", page.URL)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to push %d gist(s)", failed)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/babarot/gist/pkg/gist"
+	"github.com/babarot/gist/pkg/spin"
+	"github.com/spf13/cobra"
+)
+
+type revertCmd struct {
+	meta
+
+	to string
+}
+
+// newRevertCmd creates a new revert command
+func newRevertCmd() *cobra.Command {
+	c := &revertCmd{}
+
+	revertCmd := &cobra.Command{
+		Use:                   "revert [ID | ID/FILENAME | URL] [FILENAME] --to REVISION",
+		Short:                 "Restore gist files to an earlier revision",
+		Aliases:               []string{},
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.to == "" {
+				return errors.New("--to is required")
+			}
+			if err := c.meta.load(args); err != nil {
+				return err
+			}
+			return c.run(args)
+		},
+	}
+
+	f := revertCmd.Flags()
+	f.StringVar(&c.to, "to", "", "revision to restore (see `gist log`)")
+	f.BoolVar(&c.dryRun, "dry-run", false, "show what would be reverted without reverting")
+
+	return revertCmd
+}
+
+func (c *revertCmd) run(args []string) error {
+	page, err := c.selectPage(args)
+	if err != nil {
+		return err
+	}
+	page, err = c.checkoutPage(page)
+	if err != nil {
+		return err
+	}
+	if _, err := page.Repository(); err != nil {
+		return err
+	}
+
+	var name string
+	switch len(args) {
+	case 0:
+	case 1:
+		_, name = parseQuery(args[0])
+	default:
+		name = args[1]
+	}
+
+	target := page.ID
+	if name != "" {
+		target = page.ID + "/" + name
+	}
+
+	if c.dryRun {
+		fmt.Printf("Dry run: would revert %s to %s\n", target, c.to)
+		return nil
+	}
+
+	s := spin.New("%s Reverting...")
+	s.Start()
+	defer s.Stop()
+
+	if err := page.Revert(name, c.to); err != nil {
+		return err
+	}
+
+	c.updatePage(page.ID, func(page *gist.Page) {
+		page.UpdatedAt = time.Now()
+	})
+
+	s.Stop()
+	fmt.Printf("Reverted %s to %s: %s\n", target, c.to, page.URL)
+
+	return nil
+}
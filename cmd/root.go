@@ -17,6 +17,13 @@ var (
 	BuildSHA = "unset"
 )
 
+// options holds the global flags shared by all commands
+var options struct {
+	noInput bool
+	verbose bool
+	refresh bool
+}
+
 // newRootCmd returns the root command
 func newRootCmd() *cobra.Command {
 	rootCmd := &cobra.Command{
@@ -27,10 +34,29 @@ func newRootCmd() *cobra.Command {
 		Version:            fmt.Sprintf("%s (%s/%s)", Version, BuildTag, BuildSHA),
 	}
 
+	f := rootCmd.PersistentFlags()
+	f.BoolVar(&options.noInput, "no-input", false, "never prompt, fail instead")
+	f.BoolVarP(&options.verbose, "verbose", "v", false, "show details of errors")
+	f.BoolVar(&options.refresh, "refresh", false, "fetch all pages from the API instead of the cache")
+
 	rootCmd.AddCommand(newNewCmd())
+	rootCmd.AddCommand(newAddCmd())
+	rootCmd.AddCommand(newMvCmd())
+	rootCmd.AddCommand(newDescribeCmd())
+	rootCmd.AddCommand(newVisibilityCmd())
+	rootCmd.AddCommand(newStatusCmd())
+	rootCmd.AddCommand(newPushCmd())
+	rootCmd.AddCommand(newLogCmd())
+	rootCmd.AddCommand(newDiffCmd())
+	rootCmd.AddCommand(newRevertCmd())
+	rootCmd.AddCommand(newSyncCmd())
+	rootCmd.AddCommand(newListCmd())
+	rootCmd.AddCommand(newCatCmd())
 	rootCmd.AddCommand(newEditCmd())
 	rootCmd.AddCommand(newOpenCmd())
 	rootCmd.AddCommand(newDeleteCmd())
+	rootCmd.AddCommand(newUndeleteCmd())
+	rootCmd.AddCommand(newTrashCmd())
 	return rootCmd
 }
 
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type statusCmd struct {
+	meta
+}
+
+// newStatusCmd creates a new status command
+func newStatusCmd() *cobra.Command {
+	c := &statusCmd{}
+
+	statusCmd := &cobra.Command{
+		Use:                   "status",
+		Short:                 "Show local changes not pushed yet",
+		Aliases:               []string{"st"},
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.MaximumNArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.meta.load(args); err != nil {
+				return err
+			}
+			return c.run(args)
+		},
+	}
+
+	return statusCmd
+}
+
+func (c *statusCmd) run(args []string) error {
+	ctx := context.Background()
+	found := false
+	failed := 0
+
+	// the local repositories are only opened without updating them
+	// so that it works offline and doesn't cost a request per page
+	for _, page := range c.gist.Pages {
+		if !c.gist.IsCloned(page) {
+			continue
+		}
+		repo, err := c.gist.Open(page)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR]: %s: %v\n", page.ID, err)
+			failed++
+			continue
+		}
+		changes, err := repo.Changes()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR]: %s: %v\n", page.ID, err)
+			failed++
+			continue
+		}
+		unpushed, err := repo.HasUnpushed()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR]: %s: %v\n", page.ID, err)
+			failed++
+			continue
+		}
+		if len(changes) > 0 || unpushed {
+			// the remote-tracking branch last fetched is used if offline
+			if err := repo.Fetch(ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "[WARN]: %s: %v\n", page.ID, err)
+			}
+		}
+		ahead, behind, err := repo.Divergence(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR]: %s: %v\n", page.ID, err)
+			failed++
+			continue
+		}
+		if len(changes) == 0 && ahead == 0 && behind == 0 {
+			continue
+		}
+		found = true
+
+		var state []string
+		if len(changes) > 0 {
+			state = append(state, "dirty")
+		}
+		if ahead > 0 {
+			state = append(state, fmt.Sprintf("ahead %d", ahead))
+		}
+		if behind > 0 {
+			state = append(state, fmt.Sprintf("behind %d", behind))
+		}
+		fmt.Printf("%s (%s): %s\n", page.ID, oneline(page.Description), strings.Join(state, ", "))
+
+		var names []string
+		for name := range changes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("  %s %s\n", changes[name], name)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to get the status of %d gist(s)", failed)
+	}
+	if !found {
+		fmt.Println("Everything up-to-date")
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+type syncCmd struct {
+	meta
+}
+
+// newSyncCmd creates a new sync command
+func newSyncCmd() *cobra.Command {
+	c := &syncCmd{}
+
+	syncCmd := &cobra.Command{
+		Use:                   "sync",
+		Short:                 "Clone or update all gist pages locally",
+		Aliases:               []string{},
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.MaximumNArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.meta.init(args); err != nil {
+				return err
+			}
+			return c.run(args)
+		},
+	}
+
+	return syncCmd
+}
+
+func (c *syncCmd) run(args []string) error {
+	synced := 0
+	for _, page := range c.gist.Pages {
+		if page.Err == nil {
+			synced++
+		}
+	}
+	fmt.Printf("Synced %d/%d pages in %s\n", synced, len(c.gist.Pages), c.gist.WorkDir)
+	return nil
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/babarot/gist/pkg/gist"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+type trashCmd struct {
+	trash *gist.Trash
+
+	olderThan string
+	dryRun    bool
+}
+
+// newTrashCmd creates a new trash command
+func newTrashCmd() *cobra.Command {
+	c := &trashCmd{
+		trash: gist.NewTrash(filepath.Join(workDir(), "trash")),
+	}
+
+	trashCmd := &cobra.Command{
+		Use:                   "trash",
+		Short:                 "Manage deleted gist pages",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.MaximumNArgs(0),
+	}
+
+	listCmd := &cobra.Command{
+		Use:                   "list",
+		Short:                 "List deleted gist pages",
+		Aliases:               []string{"ls"},
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.MaximumNArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.list()
+		},
+	}
+
+	purgeCmd := &cobra.Command{
+		Use:                   "purge",
+		Short:                 "Permanently remove deleted gist pages",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.MaximumNArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.purge()
+		},
+	}
+
+	f := purgeCmd.Flags()
+	f.StringVar(&c.olderThan, "older-than", "30d", "purge pages deleted before this long ago (e.g. 30d, 12h)")
+	f.BoolVar(&c.dryRun, "dry-run", false, "show what would be purged without purging")
+
+	trashCmd.AddCommand(listCmd)
+	trashCmd.AddCommand(purgeCmd)
+
+	return trashCmd
+}
+
+func (c *trashCmd) list() error {
+	entries, err := c.trash.List()
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tDESCRIPTION\tFILES\tDELETED")
+	for _, entry := range entries {
+		var files []string
+		for _, file := range entry.Page.Files {
+			files = append(files, file.Name)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+			entry.Page.ID,
+			oneline(entry.Page.Description),
+			strings.Join(files, ", "),
+			humanize.Time(entry.DeletedAt),
+		)
+	}
+	return tw.Flush()
+}
+
+func (c *trashCmd) purge() error {
+	age, err := parseAge(c.olderThan)
+	if err != nil {
+		return err
+	}
+	before := time.Now().Add(-age)
+
+	if c.dryRun {
+		entries, err := c.trash.List()
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.DeletedAt.Before(before) {
+				fmt.Printf("Dry run: would purge %s (deleted %s)\n", entry.Page.ID, humanize.Time(entry.DeletedAt))
+			}
+		}
+		return nil
+	}
+
+	purged, err := c.trash.Purge(before)
+	for _, entry := range purged {
+		fmt.Printf("Purged: %s\n", entry.Page.ID)
+	}
+	return err
+}
+
+// parseAge parses a duration, also accepting days such as "30d"
+func parseAge(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("%s: invalid duration", s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
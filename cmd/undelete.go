@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/babarot/gist/pkg/spin"
+	"github.com/spf13/cobra"
+)
+
+type undeleteCmd struct {
+	meta
+}
+
+// newUndeleteCmd creates a new undelete command
+func newUndeleteCmd() *cobra.Command {
+	c := &undeleteCmd{}
+
+	undeleteCmd := &cobra.Command{
+		Use:                   "undelete ID",
+		Short:                 "Restore a deleted gist page from trash",
+		Aliases:               []string{"restore"},
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.meta.load(args); err != nil {
+				return err
+			}
+			return c.run(args)
+		},
+	}
+
+	f := undeleteCmd.Flags()
+	f.BoolVar(&c.dryRun, "dry-run", false, "show what would be restored without restoring")
+
+	return undeleteCmd
+}
+
+func (c *undeleteCmd) run(args []string) error {
+	// the ID is used as a filename in the trash
+	if err := checkFilename(args[0]); err != nil {
+		return fmt.Errorf("%s: invalid gist ID", args[0])
+	}
+	entry, err := c.gist.Trash.Get(args[0])
+	if err != nil {
+		return err
+	}
+
+	if c.dryRun {
+		fmt.Printf("Dry run: would restore %s as a new %s page with:\n",
+			entry.Page.ID, visibility(entry.Page.Public))
+		for _, file := range entry.Page.Files {
+			fmt.Printf("  - %s (%d bytes)\n", file.Name, len(file.Content))
+		}
+		return nil
+	}
+
+	s := spin.New("%s Restoring page...")
+	s.Start()
+	defer s.Stop()
+
+	// gist cannot be restored with the same ID,
+	// so it's recreated as a new page
+	url, err := c.gist.Create(entry.Page)
+	if err != nil {
+		return err
+	}
+
+	s.Stop()
+	fmt.Println(url)
+
+	c.gist.Trash.Remove(entry.Page.ID)
+	c.cache.Invalidate()
+	return nil
+}
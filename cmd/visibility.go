@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/babarot/gist/pkg/spin"
+	"github.com/spf13/cobra"
+)
+
+type visibilityCmd struct {
+	meta
+
+	public bool
+	secret bool
+	delete bool
+	yes    bool
+}
+
+// newVisibilityCmd creates a new visibility command
+func newVisibilityCmd() *cobra.Command {
+	c := &visibilityCmd{}
+
+	visibilityCmd := &cobra.Command{
+		Use:                   "visibility [ID | URL] --public|--secret",
+		Short:                 "Recreate gist as public or secret",
+		Aliases:               []string{},
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		SilenceErrors:         true,
+		Args:                  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.public == c.secret {
+				return errors.New("either --public or --secret is required")
+			}
+			if err := c.meta.load(args); err != nil {
+				return err
+			}
+			return c.run(args)
+		},
+	}
+
+	f := visibilityCmd.Flags()
+	f.BoolVar(&c.public, "public", false, "recreate as a public gist")
+	f.BoolVar(&c.secret, "secret", false, "recreate as a secret gist")
+	f.BoolVar(&c.delete, "delete", false, "delete the original gist after recreating")
+	f.BoolVarP(&c.yes, "yes", "y", false, "delete the original without confirmation")
+	f.BoolVar(&c.dryRun, "dry-run", false, "show what would be done without doing it")
+
+	return visibilityCmd
+}
+
+func (c *visibilityCmd) run(args []string) error {
+	page, err := c.selectPage(args)
+	if err != nil {
+		return err
+	}
+
+	if page.Public == c.public {
+		fmt.Printf("%s: already %s\n", page.ID, visibility(page.Public))
+		return nil
+	}
+
+	if c.dryRun {
+		fmt.Printf("Dry run: would recreate %s as a %s gist\n", page.URL, visibility(c.public))
+		if c.delete {
+			fmt.Printf("Dry run: would delete %s\n", page.URL)
+		}
+		return nil
+	}
+
+	if c.delete && !c.yes {
+		ok, err := c.confirm(fmt.Sprintf("Delete the original gist %s after recreating", page.ID))
+		if !ok || err != nil {
+			return err
+		}
+	}
+
+	s := spin.New("%s Recreating page...")
+	s.Start()
+	defer s.Stop()
+
+	url, err := c.gist.Recreate(page, c.public)
+	s.Stop()
+	if err != nil {
+		return err
+	}
+
+	// show the new gist first so that it's known even if the deletion fails
+	fmt.Printf("%s -> %s\n", page.URL, url)
+	c.cache.Invalidate()
+
+	if c.delete {
+		s := spin.New("%s Deleting page...")
+		s.Start()
+		err := c.gist.Delete(page)
+		s.Stop()
+		if err != nil {
+			return fmt.Errorf("%s: failed to delete the original gist: %v", page.ID, err)
+		}
+	}
+
+	return nil
+}
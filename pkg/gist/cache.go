@@ -3,12 +3,46 @@ package gist
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
+	"time"
 )
 
 type Cache struct {
-	Token string `json:"token"`
-	Pages []Page `json:"pages"`
-	Path  string `json:"-"`
+	Token  string `json:"token"`
+	Pages  []Page `json:"pages"`
+	Author Author `json:"author"`
+	Path   string `json:"-"`
+
+	// FetchedAt is the time when the pages were fetched from the API
+	FetchedAt time.Time `json:"fetched_at"`
+	// Lists records the responses of the list API keyed by the request URL
+	Lists map[string]ListPage `json:"lists"`
+	// Updates records the responses of the list API for the pages updated
+	// since the last fetch, keyed by the request URL without the time
+	Updates map[string]ListPage `json:"updates"`
+	// Version is the format of the cache file
+	Version int `json:"version"`
+	// Failures records the last checkout errors keyed by the page ID
+	// so that broken pages can be told before they're checked out
+	Failures map[string]string `json:"failures,omitempty"`
+}
+
+// cacheVersion is the current format of the cache file. The cache in
+// other formats is discarded and the pages are fetched again
+const cacheVersion = 2
+
+// ListPage records a response of the list API to make conditional requests
+type ListPage struct {
+	ETag         string   `json:"etag"`
+	LastModified string   `json:"last_modified"`
+	IDs          []string `json:"ids"`
+	NextPage     int      `json:"next_page"`
+}
+
+// Author is the identity used for commits
+type Author struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
 }
 
 func NewCache(path string) *Cache {
@@ -25,22 +59,61 @@ func (c *Cache) Open() error {
 		return err
 	}
 	defer f.Close()
-	return json.NewDecoder(f).Decode(&c)
+	if err := json.NewDecoder(f).Decode(&c); err != nil {
+		return err
+	}
+	if c.Version != cacheVersion {
+		*c = *NewCache(c.Path)
+	}
+	return nil
 }
 
 func (c *Cache) Save(pages []Page) error {
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0700); err != nil {
+		return err
+	}
 	f, err := os.Create(c.Path)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 	c.Pages = pages
+	c.Version = cacheVersion
 	// TODO: don't save token
 	// but better to think another solution to solve this
 	c.Token = ""
 	return json.NewEncoder(f).Encode(&c)
 }
 
+// IsExpired reports whether the pages were fetched longer ago than ttl
+func (c *Cache) IsExpired(ttl time.Duration) bool {
+	return c.FetchedAt.IsZero() || time.Since(c.FetchedAt) > ttl
+}
+
+// Merge returns the cached pages updated with the given pages,
+// which replace the cached ones having the same ID
+func (c *Cache) Merge(pages []Page) []Page {
+	updated := make(map[string]bool)
+	for _, page := range pages {
+		updated[page.ID] = true
+	}
+	merged := append([]Page{}, pages...)
+	for _, page := range c.Pages {
+		if !updated[page.ID] {
+			merged = append(merged, page)
+		}
+	}
+	return merged
+}
+
+// Invalidate marks the cached pages stale so that all of them are fetched
+// again on the next run. The responses recorded for conditional requests
+// are kept so that the unchanged pages of the list are still not downloaded
+func (c *Cache) Invalidate() error {
+	c.FetchedAt = time.Time{}
+	return c.Save(c.Pages)
+}
+
 func (c *Cache) Delete() error {
 	return os.Remove(c.Path)
 }
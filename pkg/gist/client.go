@@ -2,7 +2,13 @@ package gist
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
 
+	"github.com/babarot/gist/pkg/git"
 	"github.com/google/go-github/github"
 	"golang.org/x/oauth2"
 )
@@ -19,39 +25,238 @@ func NewClient(token string) Client {
 	return Client{github.NewClient(tc)}
 }
 
-// List lists gist pages
-func (c Client) List(user string) ([]Page, error) {
-	opt := &github.GistListOptions{
-		ListOptions: github.ListOptions{PerPage: 100},
+// List lists gist pages. If since is not zero, only the pages updated
+// at or after the time are listed. The requests are conditional on the
+// responses recorded in the cache if not nil, so that the unchanged pages
+// of the list are taken from the cache without costing rate limit
+func (c Client) List(user string, since time.Time, cache *Cache) ([]Page, error) {
+	if cache == nil {
+		cache = &Cache{}
 	}
+	// the updates are recorded apart since the since parameter
+	// changes every time while the responses are often the same
+	prevs := &cache.Lists
+	if !since.IsZero() {
+		prevs = &cache.Updates
+	}
+
+	cached := make(map[string]Page)
+	for _, page := range cache.Pages {
+		cached[page.ID] = page
+	}
+	lists := make(map[string]ListPage)
+	var pages []Page
+	for n := 1; n != 0; {
+		key := listURL(user, n)
+		u := key
+		if !since.IsZero() {
+			u += "&since=" + url.QueryEscape(since.UTC().Format(time.RFC3339))
+		}
+		prev, ok := (*prevs)[key]
+		results, list, err := c.listPage(user, u, prev, ok, cached)
+		if err != nil {
+			return []Page{}, err
+		}
+		pages = append(pages, results...)
+		lists[key] = list
+		n = list.NextPage
+	}
+	*prevs = lists
+	return pages, nil
+}
+
+// listPerPage is the number of gists requested per page of the list
+const listPerPage = 100
+
+func listURL(user string, page int) string {
+	u := "gists"
+	if user != "" {
+		u = fmt.Sprintf("users/%s/gists", url.PathEscape(user))
+	}
+	return fmt.Sprintf("%s?page=%d&per_page=%d", u, page, listPerPage)
+}
+
+// listPage gets a page of the list. If ok is true, the request is made
+// conditional on prev and the pages are taken from cached when not modified
+func (c Client) listPage(user, u string, prev ListPage, ok bool, cached map[string]Page) ([]Page, ListPage, error) {
+	req, err := c.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, prev, err
+	}
+	if ok {
+		if prev.ETag != "" {
+			req.Header.Set("If-None-Match", prev.ETag)
+		}
+		if prev.LastModified != "" {
+			req.Header.Set("If-Modified-Since", prev.LastModified)
+		}
+	}
+
 	var gists []*github.Gist
+	resp, err := c.Do(context.Background(), req, &gists)
+	if ok && resp != nil && resp.StatusCode == http.StatusNotModified {
+		var pages []Page
+		for _, id := range prev.IDs {
+			page, found := cached[id]
+			if !found {
+				// the cache doesn't have the page any longer, get it again
+				return c.listPage(user, u, prev, false, cached)
+			}
+			pages = append(pages, page)
+		}
+		return pages, prev, nil
+	}
+	if err != nil {
+		return nil, prev, err
+	}
+
+	list := ListPage{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		NextPage:     resp.NextPage,
+	}
+	var pages []Page
+	for _, gist := range gists {
+		pages = append(pages, newListedPage(gist, user))
+		list.IDs = append(list.IDs, gist.GetID())
+	}
+	return pages, list, nil
+}
+
+// newListedPage converts a gist from the list, which doesn't have the contents of files
+func newListedPage(gist *github.Gist, user string) Page {
+	var files []File
+	for name := range gist.Files {
+		files = append(files, File{Name: string(name)})
+	}
+	return Page{
+		ID:          gist.GetID(),
+		Description: gist.GetDescription(),
+		Public:      gist.GetPublic(),
+		CreatedAt:   gist.GetCreatedAt(),
+		UpdatedAt:   gist.GetUpdatedAt(),
+		Files:       files,
+		URL:         gist.GetHTMLURL(),
+		User:        user,
+	}
+}
+
+// Get gets a gist page including the contents of its files
+func (c Client) Get(id string) (Page, error) {
+	gist, _, err := c.Gists.Get(context.Background(), id)
+	if err != nil {
+		return Page{}, err
+	}
+	var files []File
+	for name, file := range gist.Files {
+		files = append(files, File{
+			Name:    string(name),
+			Content: file.GetContent(),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	return Page{
+		ID:          gist.GetID(),
+		Description: gist.GetDescription(),
+		Public:      gist.GetPublic(),
+		CreatedAt:   gist.GetCreatedAt(),
+		UpdatedAt:   gist.GetUpdatedAt(),
+		Files:       files,
+		URL:         gist.GetHTMLURL(),
+		User:        gist.GetOwner().GetLogin(),
+	}, nil
+}
+
+// DeleteFile deletes a file from the gist with the edit API
+func (c Client) DeleteFile(id, name string) error {
+	// a file is deleted by setting it to null,
+	// which github.GistFile cannot represent
+	body := map[string]interface{}{
+		"files": map[string]interface{}{
+			name: nil,
+		},
+	}
+	req, err := c.NewRequest("PATCH", "gists/"+id, body)
+	if err != nil {
+		return err
+	}
+	_, err = c.Do(context.Background(), req, nil)
+	return err
+}
+
+// EditFiles creates or overwrites the files of the gist with the edit API
+func (c Client) EditFiles(id string, files []File) error {
+	m := make(map[github.GistFilename]github.GistFile)
+	for _, file := range files {
+		m[github.GistFilename(file.Name)] = github.GistFile{
+			Filename: github.String(file.Name),
+			Content:  github.String(file.Content),
+		}
+	}
+	_, _, err := c.Gists.Edit(context.Background(), id, &github.Gist{Files: m})
+	return err
+}
+
+// RenameFile renames the file of the gist with the edit API
+func (c Client) RenameFile(id, from, to string) error {
+	files := map[github.GistFilename]github.GistFile{
+		github.GistFilename(from): {Filename: github.String(to)},
+	}
+	_, _, err := c.Gists.Edit(context.Background(), id, &github.Gist{Files: files})
+	return err
+}
+
+// EditDescription changes the description of the gist
+func (c Client) EditDescription(id, description string) error {
+	_, _, err := c.Gists.Edit(context.Background(), id, &github.Gist{
+		Description: github.String(description),
+	})
+	return err
+}
+
+// History returns the revisions of the gist from the newest. The API
+// doesn't tell which files are changed so they're not filled
+func (c Client) History(id string) ([]git.Revision, error) {
+	opt := &github.ListOptions{PerPage: 100}
+	var revisions []git.Revision
 	for {
-		results, resp, err := c.Gists.List(context.Background(), user, opt)
+		commits, resp, err := c.Gists.ListCommits(context.Background(), id, opt)
 		if err != nil {
-			return []Page{}, err
+			return revisions, err
+		}
+		for _, commit := range commits {
+			revisions = append(revisions, git.Revision{
+				Hash:      commit.GetVersion(),
+				Author:    commit.GetUser().GetLogin(),
+				Date:      commit.GetCommittedAt().Time,
+				Additions: commit.GetChangeStatus().GetAdditions(),
+				Deletions: commit.GetChangeStatus().GetDeletions(),
+			})
 		}
-		gists = append(gists, results...)
 		if resp.NextPage == 0 {
 			break
 		}
 		opt.Page = resp.NextPage
 	}
-	var pages []Page
-	for _, gist := range gists {
-		var files []File
-		for name := range gist.Files {
-			files = append(files, File{Name: string(name)})
-		}
-		pages = append(pages, Page{
-			ID:          gist.GetID(),
-			Description: gist.GetDescription(),
-			Public:      gist.GetPublic(),
-			CreatedAt:   gist.GetCreatedAt(),
-			UpdatedAt:   gist.GetUpdatedAt(),
-			Files:       files,
-			URL:         gist.GetHTMLURL(),
-			User:        user,
-		})
+	return revisions, nil
+}
+
+// Author returns the name and email of the authenticated user to be used
+// for commits. The noreply address is used if the email is not public
+func (c Client) Author() (name, email string, err error) {
+	user, _, err := c.Users.Get(context.Background(), "")
+	if err != nil {
+		return "", "", err
 	}
-	return pages, nil
+	name = user.GetName()
+	if name == "" {
+		name = user.GetLogin()
+	}
+	email = user.GetEmail()
+	if email == "" {
+		email = fmt.Sprintf("%d+%s@users.noreply.github.com", user.GetID(), user.GetLogin())
+	}
+	return name, email, nil
 }
@@ -2,10 +2,13 @@ package gist
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,10 +21,27 @@ type Gist struct {
 	Token  string
 	Editor string
 
+	// AuthorName and AuthorEmail are used for the commits
+	AuthorName  string
+	AuthorEmail string
+
 	Client Client
 
 	WorkDir string
 	Pages   []Page
+
+	// Trash is where pages are saved before deleted if not nil
+	Trash *Trash
+
+	// Retries is the number of times to retry a failed checkout
+	Retries int
+	// Concurrency is the number of pages checked out at the same time
+	Concurrency int
+	// Timeout limits the time to check out a page if not zero
+	Timeout time.Duration
+	// Depth limits the history cloned for a page if not zero. The whole
+	// history is fetched later when it's needed
+	Depth int
 }
 
 // Page represents gist page itself
@@ -36,6 +56,8 @@ type Page struct {
 	Files       []File    `json:"files"`
 
 	Repo *git.Repo `json:"-"`
+	// Err is the error occurred on checkout if any
+	Err error `json:"-"`
 }
 
 // File represents a single file hosted on gist
@@ -47,11 +69,22 @@ type File struct {
 	Page `json:"-"`
 }
 
+// Dir returns the directory where the page is checked out
+func (g Gist) Dir(page Page) string {
+	return filepath.Join(g.WorkDir, g.User, page.ID)
+}
+
+// IsCloned reports whether the page has been cloned locally
+func (g Gist) IsCloned(page Page) bool {
+	_, err := os.Stat(filepath.Join(g.Dir(page), ".git"))
+	return err == nil
+}
+
 func (g Gist) Files() []File {
 	var files []File
 	for _, page := range g.Pages {
 		for _, file := range page.Files {
-			path := filepath.Join(g.WorkDir, g.User, page.ID, file.Name)
+			path := filepath.Join(g.Dir(page), file.Name)
 			content, _ := ioutil.ReadFile(path)
 			files = append(files, File{
 				Name:     file.Name,
@@ -65,29 +98,83 @@ func (g Gist) Files() []File {
 	return files
 }
 
-func (g *Gist) Checkout() error {
-	ch := make(chan Page, len(g.Pages))
+// Read returns the content of the file, reading it from the local clone
+// when present and falling back to the API otherwise
+func (g Gist) Read(f File) (string, error) {
+	content, err := ioutil.ReadFile(f.FullPath)
+	if err == nil {
+		return string(content), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+	page, err := g.Client.Get(f.Page.ID)
+	if err != nil {
+		return "", err
+	}
+	for _, file := range page.Files {
+		if file.Name == f.Name {
+			return file.Content, nil
+		}
+	}
+	return "", fmt.Errorf("%s: no such file in %s", f.Name, f.Page.ID)
+}
+
+// PageError is an error which occurred on a specific page
+type PageError struct {
+	Page Page
+	Err  error
+}
+
+func (e PageError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Page.ID, e.Err)
+}
+
+func (e PageError) Unwrap() error {
+	return e.Err
+}
+
+// Checkout clones or updates the repositories of all pages. It returns the
+// errors of the pages which failed, which are also kept in Page.Err. The pages
+// left untouched to keep their local changes have an error wrapping
+// git.ErrLocalChanges but their repositories can be used as usual. Only the
+// errors of the pages which have no usable repository are kept in Page.Err
+// so that the pages failed to be updated aren't told as broken.
+// done is called every time a page is checked out if not nil
+func (g *Gist) Checkout(ctx context.Context, done func(Page)) []PageError {
+	ch := make(chan PageError, len(g.Pages))
 	wg := new(sync.WaitGroup)
 
+	concurrency := g.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+
 	for _, page := range g.Pages {
 		page := page
 		wg.Add(1)
 		go func() {
+			var err error
 			defer func() {
-				ch <- page
+				page.Err = nil
+				if page.Repo == nil {
+					page.Err = err
+				}
+				ch <- PageError{Page: page, Err: err}
+				if done != nil {
+					done(page)
+				}
 				wg.Done()
 			}()
-			repo, err := git.NewRepo(git.Config{
-				URL:      page.URL,
-				WorkDir:  filepath.Join(g.WorkDir, g.User, page.ID),
-				Username: g.User,
-				Token:    g.Token,
-			})
-			if err != nil {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				err = ctx.Err()
 				return
 			}
-			repo.CloneOrOpen(context.Background())
-			page.Repo = repo
+			page.Repo, err = g.checkout(ctx, page)
 		}()
 	}
 
@@ -97,48 +184,296 @@ func (g *Gist) Checkout() error {
 	}()
 
 	pages := []Page{}
-	for p := range ch {
-		pages = append(pages, p)
+	var errs []PageError
+	for result := range ch {
+		pages = append(pages, result.Page)
+		if result.Err != nil {
+			errs = append(errs, result)
+		}
 	}
 
 	sort.Slice(pages, func(i, j int) bool {
 		return pages[i].CreatedAt.After(pages[j].CreatedAt)
 	})
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Page.CreatedAt.After(errs[j].Page.CreatedAt)
+	})
 
 	g.Pages = pages
+	return errs
+}
+
+// checkout clones or updates the repository of the page, retrying on failure.
+// The repository is returned even with an error as long as it can be used locally
+func (g *Gist) checkout(ctx context.Context, page Page) (*git.Repo, error) {
+	repo, err := g.newRepo(page)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; ; i++ {
+		err = g.cloneOrOpen(ctx, repo)
+		if err == nil || errors.Is(err, git.ErrLocalChanges) {
+			return repo, err
+		}
+		if i >= g.Retries || ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-time.After(time.Duration(i+1) * time.Second):
+		case <-ctx.Done():
+		}
+	}
+	if openErr := repo.Open(context.Background()); openErr != nil {
+		// not cloned at all
+		return nil, err
+	}
+	return repo, err
+}
 
-	return nil
+// Open opens the local repository of the page without updating it
+func (g *Gist) Open(page Page) (*git.Repo, error) {
+	repo, err := g.newRepo(page)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.Open(context.Background()); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+func (g *Gist) newRepo(page Page) (*git.Repo, error) {
+	return git.NewRepo(git.Config{
+		URL:         page.URL,
+		WorkDir:     g.Dir(page),
+		Username:    g.User,
+		Token:       g.Token,
+		AuthorName:  g.AuthorName,
+		AuthorEmail: g.AuthorEmail,
+		Depth:       g.Depth,
+	})
+}
+
+// cloneOrOpen runs repo.CloneOrOpen within the timeout
+func (g *Gist) cloneOrOpen(ctx context.Context, repo *git.Repo) error {
+	if g.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.Timeout)
+		defer cancel()
+	}
+	return repo.CloneOrOpen(ctx)
+}
+
+// Repository returns the repository of the page which is opened
+func (p Page) Repository() (*git.Repo, error) {
+	if p.Repo == nil {
+		if p.Err != nil {
+			return nil, fmt.Errorf("%s: repository not available: %w", p.ID, p.Err)
+		}
+		return nil, fmt.Errorf("%s: repository not found", p.ID)
+	}
+	if err := p.Repo.Open(context.Background()); err != nil {
+		return nil, err
+	}
+	return p.Repo, nil
 }
 
 func (f File) HasUpdated() (bool, error) {
+	repo, err := f.Page.Repository()
+	if err != nil {
+		return false, err
+	}
+	return !repo.IsClean(), nil
+}
+
+// ErrConflict is returned when the local changes to a file
+// cannot be merged automatically with the remote ones
+var ErrConflict = errors.New("conflicts with remote changes")
+
+// Update commits the changes to the file and pushes them. If the gist has
+// been changed on the remote, the changes are merged first and ErrConflict
+// is returned if the file is left with conflict markers to be resolved.
+// If msg is empty, the commit message is generated from the changes
+func (f File) Update(msg string) error {
 	ctx := context.Background()
-	repo := f.Page.Repo
-	if repo == nil {
-		return false, fmt.Errorf("%s: repository not found", f.Name)
+	repo, err := f.Page.Repository()
+	if err != nil {
+		return err
 	}
-	if err := repo.Open(ctx); err != nil {
+	if repo.IsClean() {
+		// no need to push
+		return nil
+	}
+	merged, err := mergeRemote(ctx, repo)
+	if err != nil {
+		return err
+	}
+	paths := []string{f.Name}
+	if merged {
+		// the merge has brought back all local changes including the
+		// unpushed commits into the worktree, which are committed together
+		paths = nil
+	}
+	if msg == "" {
+		msg, err = repo.Summary(paths...)
+		if err != nil {
+			return err
+		}
+	}
+	if merged {
+		err = repo.AddAll()
+	} else {
+		err = repo.Add(f.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if err := repo.Commit(msg); err != nil {
+		return err
+	}
+	return repo.Push(ctx)
+}
+
+// mergeRemote merges the remote changes and reports whether the branch has
+// been moved onto the remote one. It returns ErrConflict if any file has
+// conflict markers, including the ones left by a previous merge and not
+// resolved yet, so that they are never committed
+func mergeRemote(ctx context.Context, repo *git.Repo) (bool, error) {
+	before, err := repo.Head()
+	if err != nil {
 		return false, err
 	}
-	return !repo.IsClean(), nil
+	conflicts, err := repo.MergeRemote(ctx)
+	if err != nil {
+		return false, err
+	}
+	after, err := repo.Head()
+	if err != nil {
+		return false, err
+	}
+	merged := before != after
+	if len(conflicts) == 0 {
+		conflicts, err = repo.Conflicts()
+		if err != nil {
+			return merged, err
+		}
+	}
+	if len(conflicts) > 0 {
+		return merged, fmt.Errorf("%s: %w", strings.Join(conflicts, ", "), ErrConflict)
+	}
+	return merged, nil
+}
+
+// prepare makes sure that the page has no local changes, which would be
+// pushed together otherwise, and brings it up to date with the remote
+func (p Page) prepare(ctx context.Context) error {
+	ok, err := p.HasChanges()
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fmt.Errorf("%s: %w, push them first with `gist push %s`", p.ID, git.ErrLocalChanges, p.ID)
+	}
+	_, err = mergeRemote(ctx, p.Repo)
+	return err
+}
+
+// HasChanges reports whether the page has local changes not pushed yet
+func (p Page) HasChanges() (bool, error) {
+	repo, err := p.Repository()
+	if err != nil {
+		return false, err
+	}
+	if !repo.IsClean() {
+		return true, nil
+	}
+	return repo.HasUnpushed()
 }
 
-func (f File) Update() error {
+// Push commits all local changes of the page and pushes them together with
+// the commits not pushed yet. If the gist has been changed on the remote,
+// the changes are merged first as with File.Update
+func (p Page) Push(msg string) error {
 	ctx := context.Background()
-	repo := f.Page.Repo
+	ok, err := p.HasChanges()
+	if err != nil || !ok {
+		return err
+	}
+	repo := p.Repo
+	if _, err := mergeRemote(ctx, repo); err != nil {
+		return err
+	}
+	if !repo.IsClean() {
+		if msg == "" {
+			msg, err = repo.Summary()
+			if err != nil {
+				return err
+			}
+		}
+		if err := repo.AddAll(); err != nil {
+			return err
+		}
+		if err := repo.Commit(msg); err != nil {
+			return err
+		}
+	}
+	return repo.Push(ctx)
+}
+
+// History returns the revisions of the page, or of the file if name is not
+// empty, from the local repository. It falls back to the API if not checked
+// out, where the revisions cannot be filtered by file
+func (g Gist) History(page Page, name string) ([]git.Revision, error) {
+	repo := page.Repo
 	if repo == nil {
-		return fmt.Errorf("%s: repository not found", f.Name)
+		if name != "" {
+			return nil, fmt.Errorf("%s: history of a file requires the local repository", name)
+		}
+		return g.Client.History(page.ID)
 	}
+	ctx := context.Background()
 	if err := repo.Open(ctx); err != nil {
+		return nil, err
+	}
+	if err := repo.Deepen(ctx); err != nil {
+		return nil, err
+	}
+	return repo.Log(name)
+}
+
+// Revert restores the file, or all files if name is empty, to the revision
+// and pushes it as a new revision
+func (p Page) Revert(name, rev string) error {
+	ctx := context.Background()
+	ok, err := p.HasChanges()
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fmt.Errorf("%s: %w", p.ID, git.ErrLocalChanges)
+	}
+	repo := p.Repo
+	if err := repo.Deepen(ctx); err != nil {
+		return err
+	}
+	hash, err := repo.ShortHash(rev)
+	if err != nil {
+		return err
+	}
+	if err := repo.Restore(rev, name); err != nil {
 		return err
 	}
 	if repo.IsClean() {
-		// no need to push
-		return nil
+		return fmt.Errorf("%s: nothing to revert, same as %s", p.ID, hash)
+	}
+	msg := fmt.Sprintf("Revert to %s", hash)
+	if name != "" {
+		msg = fmt.Sprintf("Revert %s to %s", name, hash)
 	}
-	if err := repo.Add(f.Name); err != nil {
+	if err := repo.AddAll(); err != nil {
 		return err
 	}
-	if err := repo.Commit("update"); err != nil {
+	if err := repo.Commit(msg); err != nil {
 		return err
 	}
 	return repo.Push(ctx)
@@ -163,6 +498,117 @@ func (g Gist) Create(page Page) (string, error) {
 }
 
 func (g Gist) Delete(page Page) error {
+	if g.Trash != nil {
+		snapshot, err := g.snapshot(page)
+		if err != nil {
+			return fmt.Errorf("%s: failed to take snapshot: %v", page.ID, err)
+		}
+		if err := g.Trash.Put(snapshot); err != nil {
+			return fmt.Errorf("%s: failed to move to trash: %v", page.ID, err)
+		}
+	}
 	_, err := g.Client.Gists.Delete(context.Background(), page.ID)
 	return err
 }
+
+// AddFiles adds new files to the page. They're committed through the local
+// repository if checked out, otherwise added with the API
+func (g Gist) AddFiles(page Page, files []File) error {
+	ctx := context.Background()
+	repo := page.Repo
+	if repo == nil {
+		return g.Client.EditFiles(page.ID, files)
+	}
+	if err := page.prepare(ctx); err != nil {
+		return err
+	}
+	var names []string
+	for _, file := range files {
+		path := filepath.Join(repo.Path(), file.Name)
+		if err := ioutil.WriteFile(path, []byte(file.Content), 0644); err != nil {
+			return err
+		}
+		if err := repo.Add(file.Name); err != nil {
+			return err
+		}
+		names = append(names, file.Name)
+	}
+	if err := repo.Commit(fmt.Sprintf("Add %s", strings.Join(names, ", "))); err != nil {
+		return err
+	}
+	return repo.Push(ctx)
+}
+
+// Rename renames the file in the page. It's renamed with a commit through
+// the local repository if checked out, otherwise with the API
+func (g Gist) Rename(f File, name string) error {
+	ctx := context.Background()
+	repo := f.Page.Repo
+	if repo == nil {
+		return g.Client.RenameFile(f.Page.ID, f.Name, name)
+	}
+	if err := f.Page.prepare(ctx); err != nil {
+		return err
+	}
+	if err := repo.Move(f.Name, name); err != nil {
+		return err
+	}
+	if err := repo.Commit(fmt.Sprintf("Rename %s to %s", f.Name, name)); err != nil {
+		return err
+	}
+	return repo.Push(ctx)
+}
+
+// Recreate creates a copy of the page including all files with the
+// given visibility, as the visibility of an existing gist cannot be changed
+func (g Gist) Recreate(page Page, public bool) (string, error) {
+	snapshot, err := g.snapshot(page)
+	if err != nil {
+		return "", err
+	}
+	snapshot.Public = public
+	return g.Create(snapshot)
+}
+
+// snapshot returns a copy of the page filled with its file contents
+func (g Gist) snapshot(page Page) (Page, error) {
+	if page.Repo == nil {
+		// the local files may be outdated unless checked out
+		return g.Client.Get(page.ID)
+	}
+	var files []File
+	for _, file := range page.Files {
+		path := filepath.Join(g.Dir(page), file.Name)
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return g.Client.Get(page.ID)
+		}
+		files = append(files, File{
+			Name:    file.Name,
+			Content: string(content),
+		})
+	}
+	page.Files = files
+	page.Repo = nil
+	return page, nil
+}
+
+// DeleteFile deletes a single file from the page. It's removed with a
+// commit through the local repository if checked out, otherwise with the API
+func (g Gist) DeleteFile(f File) error {
+	ctx := context.Background()
+	repo := f.Page.Repo
+	if repo == nil {
+		return g.Client.DeleteFile(f.Page.ID, f.Name)
+	}
+	if err := f.Page.prepare(ctx); err != nil {
+		return err
+	}
+	if err := repo.Remove(f.Name); err != nil {
+		return err
+	}
+	if err := repo.Commit(fmt.Sprintf("Delete %s", f.Name)); err != nil {
+		return err
+	}
+	return repo.Push(ctx)
+}
@@ -0,0 +1,112 @@
+package gist
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Trash keeps snapshots of deleted pages so that they can be restored
+type Trash struct {
+	Dir string
+}
+
+// TrashEntry represents a page in the trash
+type TrashEntry struct {
+	Page      Page      `json:"page"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+func NewTrash(dir string) *Trash {
+	return &Trash{Dir: dir}
+}
+
+func (t *Trash) path(id string) string {
+	return filepath.Join(t.Dir, id+".json")
+}
+
+// Put saves the page including its file contents into the trash
+func (t *Trash) Put(page Page) error {
+	if err := os.MkdirAll(t.Dir, 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(t.path(page.ID), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(TrashEntry{
+		Page:      page,
+		DeletedAt: time.Now(),
+	})
+}
+
+// Get returns the trashed page having the given ID
+func (t *Trash) Get(id string) (TrashEntry, error) {
+	var entry TrashEntry
+	f, err := os.Open(t.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entry, fmt.Errorf("%s: not found in trash", id)
+		}
+		return entry, err
+	}
+	defer f.Close()
+	err = json.NewDecoder(f).Decode(&entry)
+	return entry, err
+}
+
+// List returns all trashed pages, most recently deleted first
+func (t *Trash) List() ([]TrashEntry, error) {
+	var entries []TrashEntry
+	infos, err := ioutil.ReadDir(t.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return entries, err
+	}
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".json") {
+			continue
+		}
+		entry, err := t.Get(strings.TrimSuffix(info.Name(), ".json"))
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].DeletedAt.After(entries[j].DeletedAt)
+	})
+	return entries, nil
+}
+
+// Remove removes the page from the trash
+func (t *Trash) Remove(id string) error {
+	return os.Remove(t.path(id))
+}
+
+// Purge removes the pages deleted before the given time and returns them
+func (t *Trash) Purge(before time.Time) ([]TrashEntry, error) {
+	var purged []TrashEntry
+	entries, err := t.List()
+	if err != nil {
+		return purged, err
+	}
+	for _, entry := range entries {
+		if !entry.DeletedAt.Before(before) {
+			continue
+		}
+		if err := t.Remove(entry.Page.ID); err != nil {
+			return purged, err
+		}
+		purged = append(purged, entry)
+	}
+	return purged, nil
+}
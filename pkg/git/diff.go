@@ -0,0 +1,188 @@
+package git
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	utildiff "github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Diff returns the unified diff of the uncommitted changes in the worktree.
+// If path is not empty, only the changes of the file are returned
+func (r *Repo) Diff(path string) (string, error) {
+	head, err := r.headCommit()
+	if err != nil {
+		return "", err
+	}
+	changes, err := r.Changes()
+	if err != nil {
+		return "", err
+	}
+
+	var names []string
+	for name := range changes {
+		if path == "" || name == path {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var patch textPatch
+	for _, name := range names {
+		var from, to *textFile
+		old, err := head.File(name)
+		switch err {
+		case nil:
+			content, err := old.Contents()
+			if err != nil {
+				return "", err
+			}
+			from = newTextFile(name, content)
+		case object.ErrFileNotFound:
+		default:
+			return "", err
+		}
+		content, err := os.ReadFile(filepath.Join(r.workDir, name))
+		switch {
+		case err == nil:
+			to = newTextFile(name, string(content))
+		case os.IsNotExist(err):
+		default:
+			return "", err
+		}
+		patch.filePatches = append(patch.filePatches, newTextFilePatch(from, to))
+	}
+
+	return encodePatch(patch)
+}
+
+// DiffRevisions returns the unified diff between the revisions. If to is
+// empty, it's compared with HEAD. If path is not empty, only the changes
+// of the file are returned
+func (r *Repo) DiffRevisions(from, to, path string) (string, error) {
+	if to == "" {
+		to = "HEAD"
+	}
+	fromCommit, err := r.commitOf(from)
+	if err != nil {
+		return "", err
+	}
+	toCommit, err := r.commitOf(to)
+	if err != nil {
+		return "", err
+	}
+	patch, err := fromCommit.Patch(toCommit)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return encodePatch(patch)
+	}
+
+	var filtered textPatch
+	for _, fp := range patch.FilePatches() {
+		f, t := fp.Files()
+		if (f != nil && f.Path() == path) || (t != nil && t.Path() == path) {
+			filtered.filePatches = append(filtered.filePatches, fp)
+		}
+	}
+	return encodePatch(filtered)
+}
+
+func (r *Repo) commitOf(rev string) (*object.Commit, error) {
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, err
+	}
+	return r.repo.CommitObject(*hash)
+}
+
+func encodePatch(patch diff.Patch) (string, error) {
+	var buf bytes.Buffer
+	err := diff.NewUnifiedEncoder(&buf, diff.DefaultContextLines).Encode(patch)
+	return buf.String(), err
+}
+
+// textPatch implements diff.Patch for the changes not committed yet
+type textPatch struct {
+	filePatches []diff.FilePatch
+}
+
+func (p textPatch) FilePatches() []diff.FilePatch { return p.filePatches }
+func (p textPatch) Message() string               { return "" }
+
+type textFilePatch struct {
+	from, to *textFile
+	chunks   []diff.Chunk
+}
+
+func newTextFilePatch(from, to *textFile) textFilePatch {
+	var src, dst string
+	if from != nil {
+		src = from.content
+	}
+	if to != nil {
+		dst = to.content
+	}
+	var chunks []diff.Chunk
+	for _, d := range utildiff.Do(src, dst) {
+		op := diff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			op = diff.Add
+		case diffmatchpatch.DiffDelete:
+			op = diff.Delete
+		}
+		chunks = append(chunks, textChunk{content: d.Text, op: op})
+	}
+	return textFilePatch{from: from, to: to, chunks: chunks}
+}
+
+func (p textFilePatch) IsBinary() bool { return false }
+
+func (p textFilePatch) Files() (diff.File, diff.File) {
+	// return untyped nils so that the encoder can tell created/deleted files
+	var from, to diff.File
+	if p.from != nil {
+		from = p.from
+	}
+	if p.to != nil {
+		to = p.to
+	}
+	return from, to
+}
+
+func (p textFilePatch) Chunks() []diff.Chunk { return p.chunks }
+
+type textFile struct {
+	path    string
+	content string
+	hash    plumbing.Hash
+}
+
+func newTextFile(path, content string) *textFile {
+	return &textFile{
+		path:    path,
+		content: content,
+		hash:    plumbing.ComputeHash(plumbing.BlobObject, []byte(content)),
+	}
+}
+
+func (f *textFile) Hash() plumbing.Hash     { return f.hash }
+func (f *textFile) Mode() filemode.FileMode { return filemode.Regular }
+func (f *textFile) Path() string            { return f.path }
+
+type textChunk struct {
+	content string
+	op      diff.Operation
+}
+
+func (c textChunk) Content() string      { return c.content }
+func (c textChunk) Type() diff.Operation { return c.op }
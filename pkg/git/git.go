@@ -4,13 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 )
 
@@ -24,10 +29,30 @@ type Repo struct {
 	token       string
 	commitName  string
 	commitEmail string
+	depth       int
 
 	branch string
 }
 
+// Revision represents a commit in the history
+type Revision struct {
+	Hash      string     `json:"hash"`
+	Author    string     `json:"author"`
+	Email     string     `json:"email,omitempty"`
+	Date      time.Time  `json:"date"`
+	Message   string     `json:"message,omitempty"`
+	Additions int        `json:"additions"`
+	Deletions int        `json:"deletions"`
+	Files     []FileStat `json:"files,omitempty"`
+}
+
+// FileStat represents the changes made to a file in a revision
+type FileStat struct {
+	Name      string `json:"name"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
 type Config struct {
 	URL     string
 	WorkDir string
@@ -37,6 +62,9 @@ type Config struct {
 
 	AuthorName  string
 	AuthorEmail string
+
+	// Depth limits the number of commits to clone if not zero
+	Depth int
 }
 
 func NewRepo(cfg Config) (*Repo, error) {
@@ -66,7 +94,9 @@ func NewRepo(cfg Config) (*Repo, error) {
 		token:       cfg.Token,
 		commitName:  cfg.AuthorName,
 		commitEmail: cfg.AuthorEmail,
-		branch:      "master",
+		depth:       cfg.Depth,
+		// fallback until the actual branch is detected
+		branch: "master",
 	}, nil
 }
 
@@ -87,8 +117,9 @@ func (r *Repo) author() *object.Signature {
 
 func (r *Repo) Clone(ctx context.Context) error {
 	repo, err := git.PlainCloneContext(ctx, r.workDir, false, &git.CloneOptions{
-		URL:  r.url,
-		Auth: r.auth(),
+		URL:   r.url,
+		Auth:  r.auth(),
+		Depth: r.depth,
 	})
 	if err != nil {
 		return err
@@ -101,8 +132,74 @@ func (r *Repo) Clone(ctx context.Context) error {
 
 	r.repo = repo
 	r.worktree = w
+	r.detectBranch()
 
-	return nil
+	// record the default branch of the remote as git does
+	// so that it doesn't need to be asked to the remote later
+	return r.setDefaultBranch(r.branch)
+}
+
+// detectBranch sets the branch to the one checked out. A clone checks out
+// the default branch of the remote
+func (r *Repo) detectBranch() {
+	head, err := r.repo.Head()
+	if err != nil || !head.Name().IsBranch() {
+		return
+	}
+	r.branch = head.Name().Short()
+}
+
+// remoteBranch returns the default branch of the remote, that is
+// the branch which HEAD of the remote points to
+func (r *Repo) remoteBranch(ctx context.Context) (string, error) {
+	remote, err := r.repo.Remote("origin")
+	if err != nil {
+		return "", err
+	}
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: r.auth()})
+	if err != nil {
+		return "", err
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference {
+			return ref.Target().Short(), nil
+		}
+	}
+	return "", errors.New("default branch not found on remote")
+}
+
+// defaultBranch returns the default branch of the remote recorded
+// in refs/remotes/origin/HEAD, or the branch checked out if not recorded
+func (r *Repo) defaultBranch() string {
+	ref, err := r.repo.Reference(plumbing.NewRemoteHEADReferenceName("origin"), false)
+	if err != nil || ref.Type() != plumbing.SymbolicReference {
+		return r.branch
+	}
+	return strings.TrimPrefix(ref.Target().Short(), "origin/")
+}
+
+func (r *Repo) setDefaultBranch(branch string) error {
+	if branch == "" {
+		return nil
+	}
+	return r.repo.Storer.SetReference(plumbing.NewSymbolicReference(
+		plumbing.NewRemoteHEADReferenceName("origin"),
+		plumbing.NewRemoteReferenceName("origin", branch),
+	))
+}
+
+// Head returns the hash of the commit which HEAD points to
+func (r *Repo) Head() (string, error) {
+	head, err := r.headCommit()
+	if err != nil {
+		return "", err
+	}
+	return head.Hash.String(), nil
+}
+
+// Branch returns the branch of the repository
+func (r *Repo) Branch() string {
+	return r.branch
 }
 
 func (r *Repo) Objects() (map[string]string, error) {
@@ -139,10 +236,18 @@ func (r *Repo) Open(ctx context.Context) error {
 
 	r.repo = repo
 	r.worktree = w
+	r.detectBranch()
 
 	return nil
 }
 
+// ErrLocalChanges is returned when the repository is not updated
+// so as not to discard the local changes which are not pushed yet
+var ErrLocalChanges = errors.New("local changes are not pushed")
+
+// CloneOrOpen clones the repository, or opens and updates it if already
+// cloned. The update is skipped with ErrLocalChanges if it has changes
+// which would be lost
 func (r *Repo) CloneOrOpen(ctx context.Context) error {
 	_, err := os.Stat(r.workDir)
 	switch {
@@ -153,28 +258,419 @@ func (r *Repo) CloneOrOpen(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+		if !r.IsClean() {
+			return fmt.Errorf("%w: worktree has uncommitted changes", ErrLocalChanges)
+		}
+		unpushed, err := r.HasUnpushed()
+		if err != nil {
+			return err
+		}
+		if unpushed {
+			return fmt.Errorf("%w: branch has unpushed commits", ErrLocalChanges)
+		}
 		return r.Pull(ctx)
 	}
 }
 
+// HasUnpushed reports whether the local branch has commits
+// which the remote-tracking branch doesn't have
+func (r *Repo) HasUnpushed() (bool, error) {
+	head, err := r.headCommit()
+	if err != nil {
+		return false, err
+	}
+	remote, err := r.remoteCommit()
+	if err != nil {
+		return false, err
+	}
+	if head.Hash == remote.Hash {
+		return false, nil
+	}
+	ok, err := isAncestor(head, remote)
+	return !ok, err
+}
+
+// Pull updates the default branch of the remote, switching to it
+// if the default branch has been changed
 func (r *Repo) Pull(ctx context.Context) error {
-	if err := r.worktree.Checkout(&git.CheckoutOptions{
+	if err := r.Fetch(ctx); err != nil {
+		return err
+	}
+	r.branch = r.defaultBranch()
+
+	if _, err := r.remoteCommit(); err == plumbing.ErrReferenceNotFound {
+		// the branch has been pruned on fetch since the default
+		// branch has been changed on the remote
+		branch, err := r.remoteBranch(ctx)
+		if err != nil {
+			return err
+		}
+		if err := r.setDefaultBranch(branch); err != nil {
+			return err
+		}
+		r.branch = branch
+	}
+	remote, err := r.remoteCommit()
+	if err != nil {
+		return err
+	}
+
+	opts := &git.CheckoutOptions{
 		Branch: plumbing.NewBranchReferenceName(r.branch),
 		Force:  true,
-	}); err != nil {
+	}
+	if _, err := r.repo.Reference(opts.Branch, false); err == plumbing.ErrReferenceNotFound {
+		// create the local branch from the remote one
+		opts.Hash = remote.Hash
+		opts.Create = true
+	}
+	if err := r.worktree.Checkout(opts); err != nil {
 		return err
 	}
 
-	err := r.worktree.PullContext(ctx, &git.PullOptions{
-		Auth:          r.auth(),
-		ReferenceName: plumbing.NewBranchReferenceName(r.branch),
-		Force:         true,
+	// fast-forward to the fetched commit rather than pulling it again
+	return r.worktree.Reset(&git.ResetOptions{
+		Commit: remote.Hash,
+		Mode:   git.HardReset,
+	})
+}
+
+func (r *Repo) Fetch(ctx context.Context) error {
+	branch := r.defaultBranch()
+	err := r.repo.FetchContext(ctx, &git.FetchOptions{
+		Auth:       r.auth(),
+		RemoteName: "origin",
+		Prune:      true,
 	})
 	if err != nil && err != git.NoErrAlreadyUpToDate {
-		return fmt.Errorf("failed to pull: %v", err)
+		return fmt.Errorf("failed to fetch: %v", err)
 	}
+	// pruning removes refs/remotes/origin/HEAD as well,
+	// which is kept unless the branch itself has been removed
+	_, err = r.repo.Reference(plumbing.NewRemoteReferenceName("origin", branch), false)
+	if err != nil {
+		return nil
+	}
+	return r.setDefaultBranch(branch)
+}
 
-	return nil
+// IsShallow reports whether the repository doesn't have the whole history
+func (r *Repo) IsShallow() bool {
+	hashes, err := r.repo.Storer.Shallow()
+	return err == nil && len(hashes) > 0
+}
+
+// Deepen fetches the whole history if the repository is a shallow clone
+func (r *Repo) Deepen(ctx context.Context) error {
+	if !r.IsShallow() {
+		return nil
+	}
+	err := r.repo.FetchContext(ctx, &git.FetchOptions{
+		Auth:       r.auth(),
+		RemoteName: "origin",
+		Depth:      math.MaxInt32,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to fetch history: %v", err)
+	}
+	return r.repo.Storer.SetShallow(nil)
+}
+
+// isAncestor reports whether c is an ancestor of other. The commits beyond
+// the boundary of a shallow clone are treated as not found since both are
+// supposed to be newer than the boundary
+func isAncestor(c, other *object.Commit) (bool, error) {
+	ok, err := c.IsAncestor(other)
+	if err == plumbing.ErrObjectNotFound {
+		return false, nil
+	}
+	return ok, err
+}
+
+// mergeBase returns the merge base of the commits, fetching the whole
+// history if it's beyond the boundary of a shallow clone
+func (r *Repo) mergeBase(ctx context.Context, c, other *object.Commit) ([]*object.Commit, error) {
+	bases, err := c.MergeBase(other)
+	if err != plumbing.ErrObjectNotFound {
+		return bases, err
+	}
+	if err := r.Deepen(ctx); err != nil {
+		return nil, err
+	}
+	return c.MergeBase(other)
+}
+
+func (r *Repo) remoteCommit() (*object.Commit, error) {
+	ref, err := r.repo.Reference(plumbing.NewRemoteReferenceName("origin", r.branch), true)
+	if err != nil {
+		return nil, err
+	}
+	return r.repo.CommitObject(ref.Hash())
+}
+
+func (r *Repo) headCommit() (*object.Commit, error) {
+	ref, err := r.repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	return r.repo.CommitObject(ref.Hash())
+}
+
+// fileAt returns the content of the file at the given commit,
+// which is empty if the file doesn't exist there
+func fileAt(commit *object.Commit, path string) (string, error) {
+	file, err := commit.File(path)
+	if err == object.ErrFileNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return file.Contents()
+}
+
+// MergeRemote fetches the remote and, if it has commits which the local
+// doesn't have, merges their changes with the local ones, both committed
+// and uncommitted, and moves the local branch onto the remote one so that
+// the result can be committed and pushed. It returns the files which are
+// left with conflict markers
+func (r *Repo) MergeRemote(ctx context.Context) ([]string, error) {
+	var conflicts []string
+
+	if err := r.Fetch(ctx); err != nil {
+		return conflicts, err
+	}
+
+	head, err := r.headCommit()
+	if err != nil {
+		return conflicts, err
+	}
+	remote, err := r.remoteCommit()
+	if err != nil {
+		return conflicts, err
+	}
+	if head.Hash == remote.Hash {
+		return conflicts, nil
+	}
+	if ok, err := isAncestor(remote, head); err != nil || ok {
+		// the local is ahead of the remote
+		return conflicts, err
+	}
+
+	bases, err := r.mergeBase(ctx, head, remote)
+	if err != nil {
+		return conflicts, err
+	}
+	if len(bases) == 0 {
+		return conflicts, errors.New("no common ancestor with the remote")
+	}
+	base := bases[0]
+
+	paths, err := r.localChanges(base, head)
+	if err != nil {
+		return conflicts, err
+	}
+
+	merged := make(map[string]*string)
+	for _, path := range paths {
+		baseContent, err := fileAt(base, path)
+		if err != nil {
+			return conflicts, err
+		}
+		theirs, err := fileAt(remote, path)
+		if err != nil {
+			return conflicts, err
+		}
+		ours, err := os.ReadFile(filepath.Join(r.workDir, path))
+		switch {
+		case os.IsNotExist(err):
+			if theirs != baseContent {
+				// removed locally but changed on the remote, keep the remote one
+				merged[path] = &theirs
+			} else {
+				merged[path] = nil
+			}
+			continue
+		case err != nil:
+			return conflicts, err
+		}
+		content, conflict := Merge3(baseContent, string(ours), theirs)
+		if conflict {
+			conflicts = append(conflicts, path)
+		}
+		merged[path] = &content
+	}
+
+	if err := r.worktree.Reset(&git.ResetOptions{
+		Commit: remote.Hash,
+		Mode:   git.HardReset,
+	}); err != nil {
+		return conflicts, err
+	}
+	for path, content := range merged {
+		path = filepath.Join(r.workDir, path)
+		if content == nil {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return conflicts, err
+			}
+			continue
+		}
+		if err := os.WriteFile(path, []byte(*content), 0644); err != nil {
+			return conflicts, err
+		}
+	}
+
+	sort.Strings(conflicts)
+	return conflicts, nil
+}
+
+// localChanges returns the files changed since the base commit,
+// either committed until head or not committed yet including untracked ones
+func (r *Repo) localChanges(base, head *object.Commit) ([]string, error) {
+	seen := make(map[string]bool)
+
+	baseTree, err := base.Tree()
+	if err != nil {
+		return nil, err
+	}
+	headTree, err := head.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTree(baseTree, headTree)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		for _, name := range []string{change.From.Name, change.To.Name} {
+			if name != "" {
+				seen[name] = true
+			}
+		}
+	}
+
+	status, err := r.worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+	for path := range status {
+		// untracked files are included as well since they'd be removed by the reset
+		seen[path] = true
+	}
+
+	var paths []string
+	for path := range seen {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Log returns the revisions from the newest. If path is not empty,
+// only the revisions changing the file are returned
+func (r *Repo) Log(path string) ([]Revision, error) {
+	var revisions []Revision
+	opts := &git.LogOptions{}
+	if path != "" {
+		opts.FileName = &path
+	}
+	iter, err := r.repo.Log(opts)
+	if err != nil {
+		return revisions, err
+	}
+	err = iter.ForEach(func(c *object.Commit) error {
+		stats, err := c.Stats()
+		switch {
+		case err == plumbing.ErrObjectNotFound && r.IsShallow():
+			// the parent is beyond the boundary of the shallow clone
+		case err != nil:
+			return err
+		}
+		rev := Revision{
+			Hash:    c.Hash.String(),
+			Author:  c.Author.Name,
+			Email:   c.Author.Email,
+			Date:    c.Author.When,
+			Message: strings.TrimSpace(c.Message),
+		}
+		for _, stat := range stats {
+			rev.Files = append(rev.Files, FileStat{
+				Name:      stat.Name,
+				Additions: stat.Addition,
+				Deletions: stat.Deletion,
+			})
+			rev.Additions += stat.Addition
+			rev.Deletions += stat.Deletion
+		}
+		revisions = append(revisions, rev)
+		return nil
+	})
+	if err == plumbing.ErrObjectNotFound && r.IsShallow() {
+		// reached the boundary of the shallow clone
+		err = nil
+	}
+	return revisions, err
+}
+
+// ShortHash returns the abbreviated hash of the revision
+func (r *Repo) ShortHash(rev string) (string, error) {
+	commit, err := r.commitOf(rev)
+	if err != nil {
+		return "", err
+	}
+	return commit.Hash.String()[:7], nil
+}
+
+// Restore writes the file at the revision into the worktree. If path is
+// empty, the whole worktree is restored including removing the files
+// which don't exist at the revision
+func (r *Repo) Restore(rev, path string) error {
+	commit, err := r.commitOf(rev)
+	if err != nil {
+		return err
+	}
+
+	if path != "" {
+		file, err := commit.File(path)
+		if err == object.ErrFileNotFound {
+			return fmt.Errorf("%s: not found at %s", path, rev)
+		}
+		if err != nil {
+			return err
+		}
+		return r.writeFile(file)
+	}
+
+	head, err := r.headCommit()
+	if err != nil {
+		return err
+	}
+	headFiles, err := head.Files()
+	if err != nil {
+		return err
+	}
+	err = headFiles.ForEach(func(f *object.File) error {
+		if _, err := commit.File(f.Name); err == object.ErrFileNotFound {
+			return os.Remove(filepath.Join(r.workDir, f.Name))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	files, err := commit.Files()
+	if err != nil {
+		return err
+	}
+	return files.ForEach(r.writeFile)
+}
+
+func (r *Repo) writeFile(f *object.File) error {
+	content, err := f.Contents()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(r.workDir, f.Name), []byte(content), 0644)
 }
 
 func (r *Repo) Path() string {
@@ -182,9 +678,11 @@ func (r *Repo) Path() string {
 }
 
 func (r *Repo) Push(ctx context.Context) error {
+	refspec := config.RefSpec(fmt.Sprintf("refs/heads/%s:refs/heads/%s", r.branch, r.branch))
 	if err := r.repo.PushContext(ctx, &git.PushOptions{
 		Auth:       r.auth(),
 		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{refspec},
 	}); err != nil {
 		return fmt.Errorf("failed to push %v: %v", r.branch, err)
 	}
@@ -197,6 +695,21 @@ func (r *Repo) Add(path string) error {
 	return err
 }
 
+// AddAll stages all changes in the worktree including new and removed files
+func (r *Repo) AddAll() error {
+	return r.worktree.AddWithOptions(&git.AddOptions{All: true})
+}
+
+func (r *Repo) Remove(path string) error {
+	_, err := r.worktree.Remove(path)
+	return err
+}
+
+func (r *Repo) Move(from, to string) error {
+	_, err := r.worktree.Move(from, to)
+	return err
+}
+
 func (r *Repo) Commit(msg string) error {
 	_, err := r.worktree.Commit(msg, &git.CommitOptions{
 		Author: r.author(),
@@ -204,6 +717,156 @@ func (r *Repo) Commit(msg string) error {
 	return err
 }
 
+// Changes returns the files changed in the worktree with their status codes
+// such as "M" (modified), "D" (deleted) and "?" (untracked)
+func (r *Repo) Changes() (map[string]string, error) {
+	m := make(map[string]string)
+	status, err := r.worktree.Status()
+	if err != nil {
+		return m, err
+	}
+	for path, s := range status {
+		code := s.Worktree
+		if code == git.Unmodified {
+			code = s.Staging
+		}
+		if code == git.Unmodified {
+			continue
+		}
+		m[path] = string(code)
+	}
+	return m, nil
+}
+
+// Conflicts returns the changed files which still have conflict markers.
+// If paths are given, only the files are checked
+func (r *Repo) Conflicts(paths ...string) ([]string, error) {
+	var conflicts []string
+	changes, err := r.Changes()
+	if err != nil {
+		return conflicts, err
+	}
+	for path := range changes {
+		if len(paths) > 0 && !contains(paths, path) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(r.workDir, path))
+		switch {
+		case os.IsNotExist(err):
+			continue
+		case err != nil:
+			return conflicts, err
+		}
+		if HasConflictMarkers(string(content)) {
+			conflicts = append(conflicts, path)
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts, nil
+}
+
+// Summary returns a commit message summarising the changes in the worktree
+// such as "update a.txt, add b.txt". If paths are given, only the changes
+// of the files are summarised
+func (r *Repo) Summary(paths ...string) (string, error) {
+	changes, err := r.Changes()
+	if err != nil {
+		return "", err
+	}
+	verbs := []string{"update", "add", "delete", "rename"}
+	files := make(map[string][]string)
+	for path, code := range changes {
+		if len(paths) > 0 && !contains(paths, path) {
+			continue
+		}
+		verb := "update"
+		switch git.StatusCode(code[0]) {
+		case git.Added, git.Untracked:
+			verb = "add"
+		case git.Deleted:
+			verb = "delete"
+		case git.Renamed:
+			verb = "rename"
+		}
+		files[verb] = append(files[verb], path)
+	}
+	var parts []string
+	for _, verb := range verbs {
+		if len(files[verb]) == 0 {
+			continue
+		}
+		sort.Strings(files[verb])
+		parts = append(parts, verb+" "+strings.Join(files[verb], ", "))
+	}
+	if len(parts) == 0 {
+		return "update", nil
+	}
+	msg := strings.Join(parts, "; ")
+	return strings.ToUpper(msg[:1]) + msg[1:], nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// GlobalAuthor returns the user name and email in the global git config
+func GlobalAuthor() (name, email string) {
+	cfg, err := config.LoadConfig(config.GlobalScope)
+	if err != nil {
+		return "", ""
+	}
+	return cfg.User.Name, cfg.User.Email
+}
+
+// Divergence returns the number of commits which the local branch has but
+// the remote-tracking branch doesn't (ahead) and vice versa (behind)
+func (r *Repo) Divergence(ctx context.Context) (ahead, behind int, err error) {
+	head, err := r.headCommit()
+	if err != nil {
+		return 0, 0, err
+	}
+	remote, err := r.remoteCommit()
+	if err != nil {
+		return 0, 0, err
+	}
+	if head.Hash == remote.Hash {
+		return 0, 0, nil
+	}
+	bases, err := r.mergeBase(ctx, head, remote)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(bases) == 0 {
+		return 0, 0, errors.New("no common ancestor with the remote")
+	}
+	ahead, err = r.countCommits(head, bases[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	behind, err = r.countCommits(remote, bases[0])
+	return ahead, behind, err
+}
+
+// countCommits counts the commits from the commit back to base,
+// assuming that the history is linear as gists usually are
+func (r *Repo) countCommits(from, base *object.Commit) (int, error) {
+	n := 0
+	iter := object.NewCommitPreorderIter(from, nil, nil)
+	err := iter.ForEach(func(c *object.Commit) error {
+		if c.Hash == base.Hash {
+			return storer.ErrStop
+		}
+		n++
+		return nil
+	})
+	return n, err
+}
+
 func (r *Repo) IsClean() bool {
 	status, err := r.worktree.Status()
 	if err != nil {
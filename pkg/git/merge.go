@@ -0,0 +1,141 @@
+package git
+
+import (
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const (
+	markerOurs   = "<<<<<<< local"
+	markerSep    = "======="
+	markerTheirs = ">>>>>>> remote"
+)
+
+// Merge3 merges the changes made from base to ours and from base to theirs
+// line by line. The conflicting hunks are surrounded with conflict markers
+// and conflict is reported as true
+func Merge3(base, ours, theirs string) (merged string, conflict bool) {
+	baseLines := splitLines(base)
+	oursLines := splitLines(ours)
+	theirsLines := splitLines(theirs)
+
+	matchOurs := matchLines(base, ours, len(baseLines))
+	matchTheirs := matchLines(base, theirs, len(baseLines))
+
+	var b strings.Builder
+	i, a, t := 0, 0, 0
+	for i < len(baseLines) || a < len(oursLines) || t < len(theirsLines) {
+		// stable line unchanged on both sides
+		if i < len(baseLines) && matchOurs[i] == a && matchTheirs[i] == t {
+			b.WriteString(baseLines[i])
+			i, a, t = i+1, a+1, t+1
+			continue
+		}
+
+		// find the next line which is kept on both sides
+		k := i
+		for k < len(baseLines) && (matchOurs[k] < a || matchTheirs[k] < t) {
+			k++
+		}
+		ja, jt := len(oursLines), len(theirsLines)
+		if k < len(baseLines) {
+			ja, jt = matchOurs[k], matchTheirs[k]
+		}
+
+		baseChunk := baseLines[i:k]
+		oursChunk := oursLines[a:ja]
+		theirsChunk := theirsLines[t:jt]
+
+		switch {
+		case equalLines(oursChunk, baseChunk):
+			writeLines(&b, theirsChunk, false)
+		case equalLines(theirsChunk, baseChunk), equalLines(oursChunk, theirsChunk):
+			writeLines(&b, oursChunk, false)
+		default:
+			conflict = true
+			b.WriteString(markerOurs + "\n")
+			writeLines(&b, oursChunk, true)
+			b.WriteString(markerSep + "\n")
+			writeLines(&b, theirsChunk, true)
+			b.WriteString(markerTheirs + "\n")
+		}
+
+		i, a, t = k, ja, jt
+	}
+
+	return b.String(), conflict
+}
+
+// HasConflictMarkers reports whether the content still has conflict markers
+func HasConflictMarkers(content string) bool {
+	for _, line := range splitLines(content) {
+		line = strings.TrimRight(line, "\r\n")
+		if line == markerOurs || line == markerTheirs {
+			return true
+		}
+	}
+	return false
+}
+
+// splitLines splits text into lines keeping their line endings
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// matchLines returns the index of the line in other which each line
+// of base is kept as, or -1 if the line has been removed
+func matchLines(base, other string, n int) []int {
+	match := make([]int, n)
+	for i := range match {
+		match[i] = -1
+	}
+
+	dmp := diffmatchpatch.New()
+	runes1, runes2, _ := dmp.DiffLinesToRunes(base, other)
+	diffs := dmp.DiffMainRunes(runes1, runes2, false)
+
+	i, j := 0, 0
+	for _, diff := range diffs {
+		n := len([]rune(diff.Text))
+		switch diff.Type {
+		case diffmatchpatch.DiffEqual:
+			for k := 0; k < n; k++ {
+				match[i+k] = j + k
+			}
+			i, j = i+n, j+n
+		case diffmatchpatch.DiffDelete:
+			i += n
+		case diffmatchpatch.DiffInsert:
+			j += n
+		}
+	}
+	return match
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// writeLines writes lines. If terminate is true, it makes sure that the last
+// one ends with a newline so that a following conflict marker starts on its own line
+func writeLines(b *strings.Builder, lines []string, terminate bool) {
+	for _, line := range lines {
+		b.WriteString(line)
+	}
+	if terminate && len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		b.WriteString("\n")
+	}
+}
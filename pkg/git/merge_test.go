@@ -0,0 +1,88 @@
+package git
+
+import "testing"
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		ours     string
+		theirs   string
+		merged   string
+		conflict bool
+	}{
+		{
+			name:   "non-overlapping edits",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "a\nB\nc\nd\ne\n",
+			theirs: "a\nb\nc\nD\ne\n",
+			merged: "a\nB\nc\nD\ne\n",
+		},
+		{
+			name:   "same edit on both sides",
+			base:   "a\nb\nc\n",
+			ours:   "a\nB\nc\n",
+			theirs: "a\nB\nc\n",
+			merged: "a\nB\nc\n",
+		},
+		{
+			name:   "insert at EOF",
+			base:   "a\nb\n",
+			ours:   "a\nb\nc\n",
+			theirs: "A\nb\n",
+			merged: "A\nb\nc\n",
+		},
+		{
+			name:   "delete at EOF",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\n",
+			theirs: "A\nb\nc\n",
+			merged: "A\nb\n",
+		},
+		{
+			name:   "missing trailing newline",
+			base:   "a\nb\nc",
+			ours:   "A\nb\nc",
+			theirs: "a\nb\nc\nd\n",
+			merged: "A\nb\nc\nd\n",
+		},
+		{
+			name:     "conflict without trailing newline",
+			base:     "a\nb",
+			ours:     "a\nB",
+			theirs:   "a\nC",
+			merged:   "a\n<<<<<<< local\nB\n=======\nC\n>>>>>>> remote\n",
+			conflict: true,
+		},
+		{
+			name:   "empty base with the same content",
+			base:   "",
+			ours:   "x\n",
+			theirs: "x\n",
+			merged: "x\n",
+		},
+		{
+			name:     "empty base with different contents",
+			base:     "",
+			ours:     "x\n",
+			theirs:   "y\n",
+			merged:   "<<<<<<< local\nx\n=======\ny\n>>>>>>> remote\n",
+			conflict: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflict := Merge3(tt.base, tt.ours, tt.theirs)
+			if merged != tt.merged {
+				t.Errorf("merged = %q, want %q", merged, tt.merged)
+			}
+			if conflict != tt.conflict {
+				t.Errorf("conflict = %v, want %v", conflict, tt.conflict)
+			}
+			if HasConflictMarkers(merged) != tt.conflict {
+				t.Errorf("HasConflictMarkers = %v, want %v", !tt.conflict, tt.conflict)
+			}
+		})
+	}
+}
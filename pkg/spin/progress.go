@@ -0,0 +1,56 @@
+package spin
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/caarlos0/spin"
+)
+
+// Progress shows how many of the total works have been done
+type Progress struct {
+	mu     sync.Mutex
+	text   string
+	done   int
+	total  int
+	writer io.Writer
+}
+
+// NewProgress returns Progress which shows text followed by "done/total".
+// It's written to stderr so as not to mix with the output, and only when
+// stderr is a terminal
+func NewProgress(text string, total int) *Progress {
+	return &Progress{
+		text:   text,
+		total:  total,
+		writer: writer(),
+	}
+}
+
+// Start shows the progress
+func (p *Progress) Start() *Progress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.render()
+	return p
+}
+
+// Increment counts up a done work
+func (p *Progress) Increment() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	p.render()
+}
+
+// Stop hides the progress
+func (p *Progress) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprint(p.writer, spin.ClearLine)
+}
+
+func (p *Progress) render() {
+	fmt.Fprintf(p.writer, "%s%s %d/%d", spin.ClearLine, p.text, p.done, p.total)
+}
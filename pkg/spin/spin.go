@@ -1,12 +1,26 @@
 package spin
 
 import (
+	"io"
+	"io/ioutil"
+	"os"
+
 	"github.com/caarlos0/spin"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 func New(text string) *spin.Spinner {
 	// if clilog.IsEnabled() {
 	// 	return spin.New(text, spin.WithWriter(ioutil.Discard))
 	// }
-	return spin.New(text)
+	return spin.New(text, spin.WithWriter(writer()))
+}
+
+// writer returns stderr so as not to mix spinners with the output,
+// or a writer discarding them if stderr is not a terminal
+func writer() io.Writer {
+	if !terminal.IsTerminal(int(os.Stderr.Fd())) {
+		return ioutil.Discard
+	}
+	return os.Stderr
 }